@@ -1,6 +1,7 @@
 package main
 
 import (
+	"backend/email_template"
 	"backend/internal/controllers"
 	"backend/internal/infrastructures/migrations"
 	"backend/internal/infrastructures/repositories"
@@ -9,10 +10,13 @@ import (
 	"backend/pkg/cache"
 	configs "backend/pkg/config"
 	"backend/pkg/database"
+	"backend/pkg/encryption"
 	"backend/pkg/http"
 	"backend/pkg/jwt_generate"
 	"backend/pkg/logger"
 	"backend/pkg/mailer"
+	"backend/pkg/ratelimit"
+	"backend/pkg/utils"
 
 	"github.com/labstack/echo/v4"
 	"go.uber.org/fx"
@@ -21,6 +25,15 @@ import (
 func NewHttpSever() *echo.Echo {
 	return echo.New()
 }
+func NewCursorCodec(appConfig *configs.AppConfig) (*utils.CursorCodec, error) {
+	return utils.NewCursorCodec(appConfig.Pagination.CursorSecretKey)
+}
+func NewCache(lc fx.Lifecycle, appConfig *configs.AppConfig, logger logger.Logger) (cache.Cache, error) {
+	return cache.NewCache(lc, appConfig, logger)
+}
+func NewLeaderElection(lc fx.Lifecycle, locker cache.Locker, logger logger.Logger) *cache.LeaderElection {
+	return cache.NewLeaderElection(lc, locker, logger)
+}
 func main() {
 	fx.New(
 		fx.Options(
@@ -29,9 +42,20 @@ func main() {
 				logger.NewLogger,
 				http.NewContext,
 				NewHttpSever,
+				NewCursorCodec,
 				database.NewDatabase,
-				mailer.NewSMTPMailer,
-				cache.NewRedisClient,
+				database.NewUnitOfWork,
+				mailer.NewComposer,
+				mailer.NewMemoryTransport,
+				mailer.NewTransport,
+				mailer.NewOutboxWorker,
+				mailer.NewOutboxMailer,
+				email_template.NewRenderer,
+				NewCache,
+				cache.NewLocker,
+				cache.NewInvalidator,
+				NewLeaderElection,
+				ratelimit.NewLimiter,
 				jwt_generate.NewJwtGenerate,
 			),
 			repositories.Module,
@@ -39,10 +63,14 @@ func main() {
 			controllers.Module,
 			server.Module,
 			fx.Invoke(
+				encryption.Register,
+				cache.RegisterKeys,
 				server.Run,
+				mailer.RegisterOutboxWorker,
+				mailer.RegisterOutboxRetention,
 				server.ConfigMiddlewares,
-				func(dbEngine database.DBEngine) error {
-					return migrations.Migrate(dbEngine)
+				func(dbEngine database.DBEngine, appConfig *configs.AppConfig) error {
+					return migrations.Migrate(dbEngine, appConfig)
 				},
 			),
 		),
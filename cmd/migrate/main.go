@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"backend/internal/infrastructures/migrations"
+	configs "backend/pkg/config"
+	"backend/pkg/database"
+	"backend/pkg/encryption"
+)
+
+const usage = `usage: migrate <command> [flags]
+
+commands:
+  up                     apply every pending migration, then backfill missing email blind indexes
+  down [-steps n]        roll back the last n migrations (default 1)
+  status                 list migrations and whether they are applied
+  create [-dir d] <name> write an empty up/down pair
+  reencrypt              re-encrypt user PII with the active key and rebuild blind indexes
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err := run(context.Background(), os.Args[1], os.Args[2:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, command string, args []string) error {
+	if command == "create" {
+		return create(args)
+	}
+
+	appConfig, err := configs.InitAppConfig()
+	if err != nil {
+		return err
+	}
+	dbEngine, closeDb, err := database.NewDatabase(appConfig)
+	if err != nil {
+		return err
+	}
+	defer closeDb()
+
+	if command == "reencrypt" {
+		if err := encryption.Register(appConfig); err != nil {
+			return err
+		}
+		count, err := migrations.Reencrypt(ctx, dbEngine)
+		fmt.Printf("re-encrypted %d users\n", count)
+		return err
+	}
+
+	if migrations.IsSqlite(dbEngine) {
+		if command != "up" {
+			return fmt.Errorf("%s is not supported on SQLite, its schema is built with AutoMigrate", command)
+		}
+		fmt.Println("SQLite database, running AutoMigrate")
+		return migrations.AutoMigrate(dbEngine)
+	}
+	migrator := migrations.NewMigrator(dbEngine)
+
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, migration := range applied {
+			fmt.Printf("applied %d_%s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+		if err != nil {
+			return err
+		}
+		return backfill(ctx, appConfig, dbEngine)
+	case "down":
+		flags := flag.NewFlagSet("down", flag.ExitOnError)
+		steps := flags.Int("steps", 1, "number of migrations to roll back")
+		flags.Parse(args)
+		rolledBack, err := migrator.Down(ctx, *steps)
+		for _, migration := range rolledBack {
+			fmt.Printf("rolled back %d_%s\n", migration.Version, migration.Name)
+		}
+		return err
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.Applied {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%d_%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q\n%s", command, usage)
+	}
+}
+
+// backfill indexes the users written before their columns were encrypted, they are invisible to FindByEmail until then
+func backfill(ctx context.Context, appConfig *configs.AppConfig, dbEngine database.DBEngine) error {
+	if err := encryption.Register(appConfig); err != nil {
+		return err
+	}
+	count, err := migrations.BackfillBlindIndexes(ctx, dbEngine)
+	if count > 0 {
+		fmt.Printf("backfilled the blind index of %d users\n", count)
+	}
+	return err
+}
+
+func create(args []string) error {
+	flags := flag.NewFlagSet("create", flag.ExitOnError)
+	dir := flags.String("dir", "../"+migrations.SourceDir, "directory the migration files are written to")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		return fmt.Errorf("create expects a migration name\n%s", usage)
+	}
+
+	files, err := database.CreateMigration(*dir, flags.Arg(0))
+	for _, file := range files {
+		fmt.Printf("created %s\n", file)
+	}
+	return err
+}
@@ -2,45 +2,297 @@ package email_template
 
 import (
 	"bytes"
-	"html/template"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
 	"os"
-	"path/filepath"
-	"runtime"
+	"regexp"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+
+	configs "backend/pkg/config"
+	"backend/pkg/mailer"
 )
 
+//go:embed templates
+var embedded embed.FS
+
 type ConfirmAccountData struct {
 	ConfirmationURL string
 }
 type ForgotPasswordData struct {
 	Token string
 }
+type InvitationData struct {
+	InviterName      string
+	OrganizationName string
+	InvitationURL    string
+	ExpireHours      int
+}
 
 const (
-	CONFIRM_ACCOUNT = "register.html"
-	FORGOT_PASSWORD = "forgot_password.html"
+	CONFIRM_ACCOUNT = "register"
+	FORGOT_PASSWORD = "forgot_password"
+	INVITATION      = "invitation"
 )
 
-func getCurrentFilePath() string {
-	_, filePath, _, _ := runtime.Caller(0)
-	return filePath
+const (
+	// baseLocale is the language of the templates without a locale
+	baseLocale  = "en"
+	layoutsDir  = "layouts"
+	partialsDir = "partials"
+	htmlExt     = ".html"
+	textExt     = ".txt"
+)
+
+var localePattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})*$`)
+
+// Email is a rendered template, ready to be mailed
+type Email struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+func (e *Email) Message(to string) *mailer.Message {
+	return &mailer.Message{To: to, Subject: e.Subject, Text: e.Text, HTML: e.HTML}
+}
+
+// Renderer renders the emails from templates/<name>[.<locale>].html and .txt. The html variant fills the
+// "layout" of templates/layouts with its "content", the text variant does the same and defines the "subject".
+// Partials are shared by every email. Files of the override dirs take precedence over the embedded ones,
+// each template set is parsed once per locale and cached.
+type Renderer struct {
+	sources       []fs.FS
+	defaultLocale string
+	locales       map[string]bool
+	mu            sync.Mutex
+	cache         map[string]*compiledEmail
+}
+
+type compiledEmail struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template
+}
+
+func NewRenderer(appConfig *configs.AppConfig) (*Renderer, error) {
+	config := appConfig.Mail.Templates
+	root, err := fs.Sub(embedded, "templates")
+	if err != nil {
+		return nil, err
+	}
+	sources := make([]fs.FS, 0, len(config.OverrideDirs)+1)
+	for _, dir := range config.OverrideDirs {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("email template dir %s is not a directory", dir)
+		}
+		sources = append(sources, os.DirFS(dir))
+	}
+	sources = append(sources, root)
+
+	locale := NormalizeLocale(config.DefaultLocale)
+	if locale == "" {
+		locale = baseLocale
+	}
+	renderer := &Renderer{sources: sources, defaultLocale: locale, cache: make(map[string]*compiledEmail)}
+	if renderer.locales, err = renderer.scanLocales(); err != nil {
+		return nil, err
+	}
+	return renderer, nil
+}
+
+// NormalizeLocale lower cases a locale such as vi_VN into vi-vn, anything that is not a locale gives ""
+func NormalizeLocale(locale string) string {
+	locale = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(locale)), "_", "-")
+	if !localePattern.MatchString(locale) {
+		return ""
+	}
+	return locale
+}
+
+// Render renders the email name in the closest locale available: vi-vn falls back to vi, then the default locale,
+// then the file without a locale
+func (r *Renderer) Render(name string, locale string, data any) (*Email, error) {
+	compiled, err := r.compiled(name, r.candidates(locale))
+	if err != nil {
+		return nil, err
+	}
+
+	var subject, text, html bytes.Buffer
+	if err = compiled.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, fmt.Errorf("email %s subject: %w", name, err)
+	}
+	if err = compiled.text.ExecuteTemplate(&text, "layout", data); err != nil {
+		return nil, fmt.Errorf("email %s text: %w", name, err)
+	}
+	if err = compiled.html.ExecuteTemplate(&html, "layout", data); err != nil {
+		return nil, fmt.Errorf("email %s html: %w", name, err)
+	}
+	return &Email{
+		Subject: strings.Join(strings.Fields(subject.String()), " "),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+		HTML:    html.String(),
+	}, nil
+}
+
+// candidates lists the locales to try, only the ones having templates are kept so the cache stays bounded.
+// Reaching the base locale ends the list as the templates without a locale are written in it.
+func (r *Renderer) candidates(locale string) []string {
+	var chain []string
+	for _, requested := range []string{NormalizeLocale(locale), r.defaultLocale} {
+		for requested != "" {
+			if r.locales[requested] && !contains(chain, requested) {
+				chain = append(chain, requested)
+			}
+			if requested == baseLocale {
+				return append(chain, "")
+			}
+			cut := strings.LastIndex(requested, "-")
+			if cut < 0 {
+				break
+			}
+			requested = requested[:cut]
+		}
+	}
+	return append(chain, "")
 }
 
-func LoadTemplate(fileName string, data any) (string, error) {
-	dir := filepath.Dir(getCurrentFilePath())
-	content, err := os.ReadFile(filepath.Join(dir, fileName))
+func (r *Renderer) compiled(name string, locales []string) (*compiledEmail, error) {
+	key := name + "|" + strings.Join(locales, ",")
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if compiled, ok := r.cache[key]; ok {
+		return compiled, nil
+	}
+
+	htmlFiles, err := r.files(name, htmlExt, locales)
+	if err != nil {
+		return nil, err
+	}
+	textFiles, err := r.files(name, textExt, locales)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	compiled := &compiledEmail{html: htmltemplate.New(name), text: texttemplate.New(name)}
+	for _, file := range htmlFiles {
+		if _, err = compiled.html.New(file.path).Parse(file.content); err != nil {
+			return nil, err
+		}
+	}
+	for _, file := range textFiles {
+		if _, err = compiled.text.New(file.path).Parse(file.content); err != nil {
+			return nil, err
+		}
 	}
+	if compiled.html.Lookup("layout") == nil || compiled.text.Lookup("layout") == nil || compiled.text.Lookup("subject") == nil {
+		return nil, fmt.Errorf("email %s misses its layout or subject", name)
+	}
+	r.cache[key] = compiled
+	return compiled, nil
+}
+
+type templateFile struct {
+	path    string
+	content string
+}
 
-	tmpl, err := template.New("email").Parse(string(content))
+// files are the layouts and partials followed by the email itself, which parses last to override their blocks
+func (r *Renderer) files(name string, ext string, locales []string) ([]templateFile, error) {
+	var files []templateFile
+	for _, dir := range []string{layoutsDir, partialsDir} {
+		bases, err := r.bases(dir, ext)
+		if err != nil {
+			return nil, err
+		}
+		for _, base := range bases {
+			file, err := r.pick(dir+"/"+base, ext, locales)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, file)
+		}
+	}
+	file, err := r.pick(name, ext, locales)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	return append(files, file), nil
+}
+
+// pick reads the variant of the closest locale, override dirs first
+func (r *Renderer) pick(base string, ext string, locales []string) (templateFile, error) {
+	for _, locale := range locales {
+		path := base + ext
+		if locale != "" {
+			path = base + "." + locale + ext
+		}
+		for _, source := range r.sources {
+			content, err := fs.ReadFile(source, path)
+			if err == nil {
+				return templateFile{path: path, content: string(content)}, nil
+			}
+			if !errors.Is(err, fs.ErrNotExist) {
+				return templateFile{}, err
+			}
+		}
 	}
+	return templateFile{}, fmt.Errorf("email template %s%s not found", base, ext)
+}
 
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
-		return "", err
+// bases lists the template names of dir without their locale
+func (r *Renderer) bases(dir string, ext string) ([]string, error) {
+	var bases []string
+	for _, source := range r.sources {
+		entries, err := fs.ReadDir(source, dir)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), ext) {
+				continue
+			}
+			base, _, _ := strings.Cut(strings.TrimSuffix(entry.Name(), ext), ".")
+			if !contains(bases, base) {
+				bases = append(bases, base)
+			}
+		}
 	}
+	return bases, nil
+}
 
-	return buf.String(), nil
+// scanLocales collects the locales some template is written in
+func (r *Renderer) scanLocales() (map[string]bool, error) {
+	locales := make(map[string]bool)
+	for _, source := range r.sources {
+		err := fs.WalkDir(source, ".", func(path string, entry fs.DirEntry, err error) error {
+			if err != nil || entry.IsDir() {
+				return err
+			}
+			parts := strings.Split(entry.Name(), ".")
+			if len(parts) == 3 && localePattern.MatchString(parts[1]) {
+				locales[parts[1]] = true
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return locales, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
 }
@@ -5,6 +5,7 @@ import (
 	auth_service "backend/internal/services"
 	app_errors "backend/pkg/errors"
 	"backend/pkg/logger"
+	"backend/pkg/ratelimit"
 	"backend/pkg/response"
 	"net/http"
 
@@ -16,16 +17,19 @@ import (
 type AuthController struct {
 	auth_service *auth_service.IdentityService
 	logger       logger.Logger
+	limiter      *ratelimit.Limiter
 }
 
-func NewAuthController(auth_service *auth_service.IdentityService, logger logger.Logger) app_http.Controller {
-	return &AuthController{auth_service: auth_service, logger: logger}
+func NewAuthController(auth_service *auth_service.IdentityService, logger logger.Logger, limiter *ratelimit.Limiter) app_http.Controller {
+	return &AuthController{auth_service: auth_service, logger: logger, limiter: limiter}
 }
 
 func (c *AuthController) RegisterRoute(r *echo.Group) {
-	r.POST("/accounts/register", c.Register)
-	r.POST("/accounts/verify-email", c.VerifyEmail)
-	r.POST("/accounts/login", c.Login)
+	r.POST("/accounts/register", c.Register, c.limiter.Middleware("register"))
+	r.POST("/accounts/verify-email", c.VerifyEmail, c.limiter.Middleware("otp"))
+	r.POST("/accounts/login", c.Login, c.limiter.Middleware("login"))
+	r.POST("/accounts/forgot-password", c.ForgotPassword, c.limiter.Middleware("otp"))
+	r.POST("/accounts/reset-password", c.ResetPassword, c.limiter.Middleware("otp"))
 }
 
 func (c *AuthController) Register(ctx echo.Context) error {
@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"net/http"
+
+	"backend/internal/infrastructures/entities"
+	"backend/internal/services"
+	"backend/pkg/cache"
+	configs "backend/pkg/config"
+	app_errors "backend/pkg/errors"
+	app_http "backend/pkg/http"
+	"backend/pkg/middlewares"
+	"backend/pkg/response"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type CacheController struct {
+	app_http.BaseController
+	cacheService *services.CacheService
+	roleChecker  middlewares.RoleChecker
+	redisCache   cache.Cache
+	appConfig    *configs.AppConfig
+}
+
+func NewCacheController(cacheService *services.CacheService, roleChecker middlewares.RoleChecker,
+	redisCache cache.Cache, appConfig *configs.AppConfig) app_http.Controller {
+	return &CacheController{cacheService: cacheService, roleChecker: roleChecker, redisCache: redisCache, appConfig: appConfig}
+}
+
+func (c *CacheController) RegisterRoute(r *echo.Group) {
+	admin := []echo.MiddlewareFunc{
+		middlewares.ValidateTokenMiddleware(c.appConfig, c.redisCache),
+		middlewares.RequireRoleMiddleware(c.roleChecker, entities.Role_Admin),
+	}
+	r.GET("/admin/cache/keys", c.KeyTemplates, admin...)
+	r.DELETE("/admin/cache/keys", c.Purge, admin...)
+	r.DELETE("/admin/cache/tags/:tag", c.InvalidateTag, admin...)
+	r.DELETE("/admin/cache/users/:id", c.InvalidateUser, admin...)
+}
+
+func (c *CacheController) KeyTemplates(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, c.cacheService.KeyTemplates())
+}
+
+// Purge deletes the keys matching the pattern query parameter, a glob such as identity:user:*
+func (c *CacheController) Purge(ctx echo.Context) error {
+	result := c.cacheService.Purge(ctx.Request().Context(), ctx.QueryParam("pattern"))
+	if !result.IsSuccess {
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+	return ctx.JSON(http.StatusOK, result)
+}
+
+func (c *CacheController) InvalidateTag(ctx echo.Context) error {
+	result := c.cacheService.InvalidateTag(ctx.Request().Context(), ctx.Param("tag"))
+	if !result.IsSuccess {
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+	return ctx.JSON(http.StatusOK, result)
+}
+
+func (c *CacheController) InvalidateUser(ctx echo.Context) error {
+	userId, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.Failure(app_errors.NewGeneralError(app_errors.DataInvalid)))
+	}
+	result := c.cacheService.InvalidateUser(ctx.Request().Context(), userId)
+	if !result.IsSuccess {
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+	return ctx.JSON(http.StatusOK, result)
+}
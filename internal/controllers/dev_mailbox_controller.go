@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+
+	"backend/pkg/environment"
+	app_errors "backend/pkg/errors"
+	app_http "backend/pkg/http"
+	"backend/pkg/mailer"
+	"backend/pkg/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+// DevMailboxController shows the mails captured by the memory transport, its routes only exist in development
+type DevMailboxController struct {
+	memory *mailer.MemoryTransport
+}
+
+func NewDevMailboxController(memory *mailer.MemoryTransport) app_http.Controller {
+	return &DevMailboxController{memory: memory}
+}
+
+func (c *DevMailboxController) RegisterRoute(r *echo.Group) {
+	if !environment.GetEnvironment().IsDevelopment() {
+		return
+	}
+	r.GET("/dev/mailbox", c.List)
+	r.POST("/dev/mailbox/clear", c.Clear)
+	r.GET("/dev/mailbox/:id", c.Show)
+	r.GET("/dev/mailbox/:id/raw", c.Raw)
+}
+
+func (c *DevMailboxController) List(ctx echo.Context) error {
+	return c.render(ctx, "list", c.memory.Mails())
+}
+
+func (c *DevMailboxController) Show(ctx echo.Context) error {
+	mail, ok := c.memory.Find(ctx.Param("id"))
+	if !ok {
+		return ctx.JSON(http.StatusNotFound, response.Failure(app_errors.NewGeneralError(app_errors.NotFound)))
+	}
+	return c.render(ctx, "show", mail)
+}
+
+func (c *DevMailboxController) Raw(ctx echo.Context) error {
+	mail, ok := c.memory.Find(ctx.Param("id"))
+	if !ok {
+		return ctx.JSON(http.StatusNotFound, response.Failure(app_errors.NewGeneralError(app_errors.NotFound)))
+	}
+	return ctx.Blob(http.StatusOK, echo.MIMETextPlainCharsetUTF8, mail.Raw)
+}
+
+func (c *DevMailboxController) Clear(ctx echo.Context) error {
+	c.memory.Clear()
+	return ctx.Redirect(http.StatusSeeOther, "../mailbox")
+}
+
+func (c *DevMailboxController) render(ctx echo.Context, name string, data any) error {
+	var page bytes.Buffer
+	if err := mailboxPages.ExecuteTemplate(&page, name, data); err != nil {
+		return err
+	}
+	return ctx.HTMLBlob(http.StatusOK, page.Bytes())
+}
+
+// links are relative so the pages work under any group prefix
+var mailboxPages = template.Must(template.New("mailbox").Parse(`
+{{define "head"}}<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="UTF-8" />
+<title>Mailbox</title>
+<style>
+body { font-family: Arial, sans-serif; color: #333; margin: 20px; }
+table { border-collapse: collapse; width: 100%; }
+th, td { text-align: left; padding: 6px 10px; border-bottom: 1px solid #ddd; }
+pre { white-space: pre-wrap; background: #f6f6f6; padding: 10px; }
+iframe { width: 100%; height: 600px; border: 1px solid #ddd; }
+</style>
+</head>
+<body>{{end}}
+
+{{define "list"}}{{template "head"}}
+<h1>Mailbox</h1>
+<form method="post" action="mailbox/clear"><button type="submit">Clear</button></form>
+<table>
+<tr><th>Sent at</th><th>To</th><th>Subject</th></tr>
+{{range .}}<tr>
+<td>{{.SentAt.Format "2006-01-02 15:04:05"}}</td>
+<td>{{.Message.To}}</td>
+<td><a href="mailbox/{{.Id}}">{{.Message.Subject}}</a></td>
+</tr>{{else}}<tr><td colspan="3">No mail captured yet</td></tr>{{end}}
+</table>
+</body>
+</html>{{end}}
+
+{{define "show"}}{{template "head"}}
+<p><a href="../mailbox">Back</a> | <a href="{{.Id}}/raw">Raw</a></p>
+<h1>{{.Message.Subject}}</h1>
+<table>
+<tr><th>From</th><td>{{.Message.From}}</td></tr>
+<tr><th>To</th><td>{{.Message.To}}</td></tr>
+{{with .Message.Cc}}<tr><th>Cc</th><td>{{range .}}{{.}} {{end}}</td></tr>{{end}}
+{{with .Message.Bcc}}<tr><th>Bcc</th><td>{{range .}}{{.}} {{end}}</td></tr>{{end}}
+<tr><th>Sent at</th><td>{{.SentAt.Format "2006-01-02 15:04:05"}}</td></tr>
+{{range .Message.Attachments}}<tr><th>Attachment</th><td>{{.Filename}} ({{.ContentType}})</td></tr>{{end}}
+</table>
+{{with .Message.HTML}}<h2>HTML</h2>
+<iframe sandbox srcdoc="{{.}}"></iframe>{{end}}
+{{with .Message.Text}}<h2>Text</h2>
+<pre>{{.}}</pre>{{end}}
+</body>
+</html>{{end}}
+`))
@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"backend/internal/models/responses"
+	"backend/pkg/database"
+	app_errors "backend/pkg/errors"
+	app_http "backend/pkg/http"
+	"backend/pkg/logger"
+	"backend/pkg/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+const readinessTimeout = 2 * time.Second
+
+type HealthController struct {
+	dbEngine database.DBEngine
+	logger   logger.Logger
+}
+
+func NewHealthController(dbEngine database.DBEngine, logger logger.Logger) app_http.Controller {
+	return &HealthController{dbEngine: dbEngine, logger: logger}
+}
+
+func (c *HealthController) RegisterRoute(r *echo.Group) {
+	r.GET("/health/live", c.Live)
+	r.GET("/health/ready", c.Ready)
+}
+
+func (c *HealthController) Live(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, response.Success(true))
+}
+
+// Ready is public, it only tells whether the database answers. The error and the pool stats go to the log.
+func (c *HealthController) Ready(ctx echo.Context) error {
+	pingCtx, cancel := context.WithTimeout(ctx.Request().Context(), readinessTimeout)
+	defer cancel()
+
+	if err := c.dbEngine.Ping(pingCtx); err != nil {
+		stats := c.dbEngine.Stats()
+		c.logger.WithContext(ctx.Request().Context()).Errorf("Readiness check failed: %v (open %d, in use %d, idle %d, max open %d, wait count %d, wait %s)",
+			err, stats.OpenConnections, stats.InUse, stats.Idle, stats.MaxOpenConnections, stats.WaitCount, stats.WaitDuration)
+		result := &responses.HealthResponse{Status: "unavailable", Reason: "database unavailable"}
+		return ctx.JSON(http.StatusServiceUnavailable, response.FailureWithData(result, app_errors.NewGeneralError(app_errors.DatabaseError)))
+	}
+	return ctx.JSON(http.StatusOK, response.Success(&responses.HealthResponse{Status: "ready"}))
+}
@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	"net/http"
+
+	"backend/internal/infrastructures/entities"
+	"backend/internal/services"
+	"backend/pkg/cache"
+	configs "backend/pkg/config"
+	app_http "backend/pkg/http"
+	"backend/pkg/middlewares"
+
+	"github.com/labstack/echo/v4"
+)
+
+type HistoryController struct {
+	app_http.BaseController
+	historyService *services.HistoryService
+	roleChecker    middlewares.RoleChecker
+	redisCache     cache.Cache
+	appConfig      *configs.AppConfig
+}
+
+func NewHistoryController(historyService *services.HistoryService, roleChecker middlewares.RoleChecker,
+	redisCache cache.Cache, appConfig *configs.AppConfig) app_http.Controller {
+	return &HistoryController{historyService: historyService, roleChecker: roleChecker, redisCache: redisCache, appConfig: appConfig}
+}
+
+func (c *HistoryController) RegisterRoute(r *echo.Group) {
+	r.GET("/admin/history/:entityId", c.History,
+		middlewares.ValidateTokenMiddleware(c.appConfig, c.redisCache),
+		middlewares.RequireRoleMiddleware(c.roleChecker, entities.Role_Admin))
+}
+
+// History lists the changes of an entity, the type query parameter (User, Role, UserRole) narrows it down
+func (c *HistoryController) History(ctx echo.Context) error {
+	result := c.historyService.GetHistory(ctx.Request().Context(), ctx.QueryParam("type"), ctx.Param("entityId"))
+	if !result.IsSuccess {
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+	return ctx.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"crypto/subtle"
+	"io"
+	"net/http"
+
+	"backend/internal/infrastructures/entities"
+	"backend/internal/services"
+	"backend/pkg/cache"
+	configs "backend/pkg/config"
+	app_errors "backend/pkg/errors"
+	app_http "backend/pkg/http"
+	"backend/pkg/middlewares"
+	"backend/pkg/response"
+	"backend/pkg/utils"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+const headerWebhookToken = "X-Webhook-Token"
+
+type MailController struct {
+	app_http.BaseController
+	mailService *services.MailService
+	roleChecker middlewares.RoleChecker
+	redisCache  cache.Cache
+	appConfig   *configs.AppConfig
+	codec       *utils.CursorCodec
+}
+
+func NewMailController(mailService *services.MailService, roleChecker middlewares.RoleChecker,
+	redisCache cache.Cache, appConfig *configs.AppConfig, codec *utils.CursorCodec) app_http.Controller {
+	return &MailController{mailService: mailService, roleChecker: roleChecker, redisCache: redisCache, appConfig: appConfig, codec: codec}
+}
+
+func (c *MailController) RegisterRoute(r *echo.Group) {
+	admin := []echo.MiddlewareFunc{
+		middlewares.ValidateTokenMiddleware(c.appConfig, c.redisCache),
+		middlewares.RequireRoleMiddleware(c.roleChecker, entities.Role_Admin),
+	}
+	r.GET("/admin/mail/outbox", c.List, admin...)
+	r.GET("/admin/mail/outbox/:id", c.Get, admin...)
+	r.POST("/admin/mail/outbox/:id/resend", c.Resend, admin...)
+	r.POST("/webhooks/mail/:provider", c.BounceWebhook)
+}
+
+// List lists the outbox a page at a time with the size, cursor and orderBy query parameters, status narrows it down
+func (c *MailController) List(ctx echo.Context) error {
+	page, err := utils.ToCursorPagination(ctx, c.codec)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.Failure(app_errors.NewGeneralError(app_errors.DataInvalid)))
+	}
+	result := c.mailService.ListOutbox(ctx.Request().Context(), ctx.QueryParam("status"), page)
+	if !result.IsSuccess {
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+	return ctx.JSON(http.StatusOK, result)
+}
+
+func (c *MailController) Get(ctx echo.Context) error {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.Failure(app_errors.NewGeneralError(app_errors.DataInvalid)))
+	}
+	result := c.mailService.GetOutbox(ctx.Request().Context(), id)
+	if !result.IsSuccess {
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+	return ctx.JSON(http.StatusOK, result)
+}
+
+func (c *MailController) Resend(ctx echo.Context) error {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.Failure(app_errors.NewGeneralError(app_errors.DataInvalid)))
+	}
+	result := c.mailService.Resend(ctx.Request().Context(), id)
+	if !result.IsSuccess {
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// BounceWebhook receives the bounce and complaint notifications of sendgrid, mailgun or ses,
+// the caller proves itself with the configured token
+func (c *MailController) BounceWebhook(ctx echo.Context) error {
+	token := ctx.QueryParam("token")
+	if token == "" {
+		token = ctx.Request().Header.Get(headerWebhookToken)
+	}
+	expected := c.appConfig.Mail.Webhook.Token
+	if expected == "" || subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid webhook token")
+	}
+
+	body, err := io.ReadAll(ctx.Request().Body)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.Failure(app_errors.NewGeneralError(app_errors.DataInvalid)))
+	}
+	result := c.mailService.HandleBounceWebhook(ctx.Request().Context(), ctx.Param("provider"), body)
+	if !result.IsSuccess {
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+	return ctx.JSON(http.StatusOK, result)
+}
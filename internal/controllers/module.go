@@ -10,5 +10,12 @@ var Module = fx.Module("controllers",
 	fx.Provide(
 		fx.Annotate(NewAuthController, fx.As(new(app_http.Controller)), fx.ResultTags(`group:"controllers"`)),
 		fx.Annotate(NewUserController, fx.As(new(app_http.Controller)), fx.ResultTags(`group:"controllers"`)),
+		fx.Annotate(NewTenantController, fx.As(new(app_http.Controller)), fx.ResultTags(`group:"controllers"`)),
+		fx.Annotate(NewOrganizationController, fx.As(new(app_http.Controller)), fx.ResultTags(`group:"controllers"`)),
+		fx.Annotate(NewHistoryController, fx.As(new(app_http.Controller)), fx.ResultTags(`group:"controllers"`)),
+		fx.Annotate(NewCacheController, fx.As(new(app_http.Controller)), fx.ResultTags(`group:"controllers"`)),
+		fx.Annotate(NewMailController, fx.As(new(app_http.Controller)), fx.ResultTags(`group:"controllers"`)),
+		fx.Annotate(NewDevMailboxController, fx.As(new(app_http.Controller)), fx.ResultTags(`group:"controllers"`)),
+		fx.Annotate(NewHealthController, fx.As(new(app_http.Controller)), fx.ResultTags(`group:"controllers"`)),
 	),
 )
@@ -0,0 +1,166 @@
+package controllers
+
+import (
+	"net/http"
+
+	identity_errors "backend/internal/infrastructures/errors"
+	"backend/internal/models/requests"
+	"backend/internal/services"
+	"backend/pkg/cache"
+	configs "backend/pkg/config"
+	app_errors "backend/pkg/errors"
+	app_http "backend/pkg/http"
+	"backend/pkg/middlewares"
+	"backend/pkg/response"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type OrganizationController struct {
+	app_http.BaseController
+	organizationService *services.OrganizationService
+	redisCache          cache.Cache
+	appConfig           *configs.AppConfig
+}
+
+func NewOrganizationController(organizationService *services.OrganizationService,
+	redisCache cache.Cache, appConfig *configs.AppConfig) app_http.Controller {
+	return &OrganizationController{organizationService: organizationService, redisCache: redisCache, appConfig: appConfig}
+}
+
+func (c *OrganizationController) RegisterRoute(r *echo.Group) {
+	auth := middlewares.ValidateTokenMiddleware(c.appConfig, c.redisCache)
+	r.POST("/organizations", c.Create, auth)
+	r.GET("/organizations", c.List, auth)
+	r.GET("/organizations/:id/members", c.Members, auth)
+	r.DELETE("/organizations/:id/members/:userId", c.RemoveMember, auth)
+	r.POST("/organizations/:id/invitations", c.Invite, auth)
+	r.POST("/organizations/:id/transfer-ownership", c.TransferOwnership, auth)
+	r.POST("/organizations/:id/teams", c.CreateTeam, auth)
+	r.GET("/organizations/:id/teams", c.Teams, auth)
+	r.POST("/organizations/:id/teams/:teamId/members", c.AddTeamMember, auth)
+	r.POST("/invitations/accept", c.AcceptInvitation)
+}
+
+func (c *OrganizationController) Create(ctx echo.Context) error {
+	var request requests.CreateOrganizationRequest
+	if err := ctx.Bind(&request); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.Failure(app_errors.NewGeneralError(app_errors.DataInvalid)))
+	}
+	result := c.organizationService.CreateOrganization(ctx.Request().Context(), c.CurrentUser(ctx).UserId, request)
+	return c.respond(ctx, result.IsSuccess, result.Code, result)
+}
+
+func (c *OrganizationController) List(ctx echo.Context) error {
+	result := c.organizationService.ListOrganizations(ctx.Request().Context(), c.CurrentUser(ctx).UserId)
+	return c.respond(ctx, result.IsSuccess, result.Code, result)
+}
+
+func (c *OrganizationController) Members(ctx echo.Context) error {
+	organizationId, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.Failure(app_errors.NewGeneralError(app_errors.DataInvalid)))
+	}
+	result := c.organizationService.ListMembers(ctx.Request().Context(), c.CurrentUser(ctx).UserId, organizationId)
+	return c.respond(ctx, result.IsSuccess, result.Code, result)
+}
+
+func (c *OrganizationController) RemoveMember(ctx echo.Context) error {
+	organizationId, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.Failure(app_errors.NewGeneralError(app_errors.DataInvalid)))
+	}
+	memberId, err := uuid.Parse(ctx.Param("userId"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.Failure(app_errors.NewGeneralError(app_errors.DataInvalid)))
+	}
+	result := c.organizationService.RemoveMember(ctx.Request().Context(), c.CurrentUser(ctx).UserId, organizationId, memberId)
+	return c.respond(ctx, result.IsSuccess, result.Code, result)
+}
+
+func (c *OrganizationController) Invite(ctx echo.Context) error {
+	organizationId, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.Failure(app_errors.NewGeneralError(app_errors.DataInvalid)))
+	}
+	var request requests.InviteMemberRequest
+	if err := ctx.Bind(&request); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.Failure(app_errors.NewGeneralError(app_errors.DataInvalid)))
+	}
+	result := c.organizationService.Invite(ctx.Request().Context(), c.CurrentUser(ctx).UserId, organizationId, request)
+	return c.respond(ctx, result.IsSuccess, result.Code, result)
+}
+
+func (c *OrganizationController) TransferOwnership(ctx echo.Context) error {
+	organizationId, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.Failure(app_errors.NewGeneralError(app_errors.DataInvalid)))
+	}
+	var request requests.TransferOwnershipRequest
+	if err := ctx.Bind(&request); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.Failure(app_errors.NewGeneralError(app_errors.DataInvalid)))
+	}
+	result := c.organizationService.TransferOwnership(ctx.Request().Context(), c.CurrentUser(ctx).UserId, organizationId, request)
+	return c.respond(ctx, result.IsSuccess, result.Code, result)
+}
+
+func (c *OrganizationController) CreateTeam(ctx echo.Context) error {
+	organizationId, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.Failure(app_errors.NewGeneralError(app_errors.DataInvalid)))
+	}
+	var request requests.CreateTeamRequest
+	if err := ctx.Bind(&request); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.Failure(app_errors.NewGeneralError(app_errors.DataInvalid)))
+	}
+	result := c.organizationService.CreateTeam(ctx.Request().Context(), c.CurrentUser(ctx).UserId, organizationId, request)
+	return c.respond(ctx, result.IsSuccess, result.Code, result)
+}
+
+func (c *OrganizationController) Teams(ctx echo.Context) error {
+	organizationId, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.Failure(app_errors.NewGeneralError(app_errors.DataInvalid)))
+	}
+	result := c.organizationService.ListTeams(ctx.Request().Context(), c.CurrentUser(ctx).UserId, organizationId)
+	return c.respond(ctx, result.IsSuccess, result.Code, result)
+}
+
+func (c *OrganizationController) AddTeamMember(ctx echo.Context) error {
+	organizationId, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.Failure(app_errors.NewGeneralError(app_errors.DataInvalid)))
+	}
+	teamId, err := uuid.Parse(ctx.Param("teamId"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.Failure(app_errors.NewGeneralError(app_errors.DataInvalid)))
+	}
+	var request requests.AddTeamMemberRequest
+	if err := ctx.Bind(&request); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.Failure(app_errors.NewGeneralError(app_errors.DataInvalid)))
+	}
+	result := c.organizationService.AddTeamMember(ctx.Request().Context(), c.CurrentUser(ctx).UserId, organizationId, teamId, request)
+	return c.respond(ctx, result.IsSuccess, result.Code, result)
+}
+
+func (c *OrganizationController) AcceptInvitation(ctx echo.Context) error {
+	var request requests.AcceptInvitationRequest
+	if err := ctx.Bind(&request); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.Failure(app_errors.NewGeneralError(app_errors.DataInvalid)))
+	}
+	result := c.organizationService.AcceptInvitation(ctx.Request().Context(), request)
+	return c.respond(ctx, result.IsSuccess, result.Code, result)
+}
+
+// respond maps permission failures to 403, any other failure to 400
+func (c *OrganizationController) respond(ctx echo.Context, isSuccess bool, code int, result any) error {
+	if isSuccess {
+		return ctx.JSON(http.StatusOK, result)
+	}
+	switch identity_errors.IdentityErrorValue(code) {
+	case identity_errors.NotOrganizationMember, identity_errors.OrganizationPermissionDenied:
+		return ctx.JSON(http.StatusForbidden, result)
+	}
+	return ctx.JSON(http.StatusBadRequest, result)
+}
@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"net/http"
+
+	"backend/internal/models/requests"
+	"backend/internal/services"
+	"backend/pkg/cache"
+	configs "backend/pkg/config"
+	app_errors "backend/pkg/errors"
+	app_http "backend/pkg/http"
+	"backend/pkg/middlewares"
+	"backend/pkg/response"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type TenantController struct {
+	app_http.BaseController
+	tenantService *services.TenantService
+	redisCache    cache.Cache
+	appConfig     *configs.AppConfig
+}
+
+func NewTenantController(tenantService *services.TenantService,
+	redisCache cache.Cache, appConfig *configs.AppConfig) app_http.Controller {
+	return &TenantController{tenantService: tenantService, redisCache: redisCache, appConfig: appConfig}
+}
+
+func (c *TenantController) RegisterRoute(r *echo.Group) {
+	r.POST("/tenants", c.Create, middlewares.ValidateTokenMiddleware(c.appConfig, c.redisCache))
+	r.GET("/tenants", c.List, middlewares.ValidateTokenMiddleware(c.appConfig, c.redisCache))
+	r.POST("/tenants/:id/token", c.Switch, middlewares.ValidateTokenMiddleware(c.appConfig, c.redisCache))
+}
+
+func (c *TenantController) Create(ctx echo.Context) error {
+	var request requests.CreateTenantRequest
+	if err := ctx.Bind(&request); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.Failure(app_errors.NewGeneralError(app_errors.DataInvalid)))
+	}
+	result := c.tenantService.CreateTenant(ctx.Request().Context(), c.CurrentUser(ctx).UserId, request)
+	if !result.IsSuccess {
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+	return ctx.JSON(http.StatusOK, result)
+}
+
+func (c *TenantController) List(ctx echo.Context) error {
+	result := c.tenantService.ListTenants(ctx.Request().Context(), c.CurrentUser(ctx).UserId)
+	if !result.IsSuccess {
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+	return ctx.JSON(http.StatusOK, result)
+}
+
+func (c *TenantController) Switch(ctx echo.Context) error {
+	tenantId, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.Failure(app_errors.NewGeneralError(app_errors.DataInvalid)))
+	}
+	currentUser := c.CurrentUser(ctx)
+	result := c.tenantService.SwitchTenant(ctx.Request().Context(), currentUser.UserId, currentUser.Email, tenantId)
+	if !result.IsSuccess {
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+	return ctx.JSON(http.StatusOK, result)
+}
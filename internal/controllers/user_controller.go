@@ -3,11 +3,14 @@ package controllers
 import (
 	"net/http"
 
+	"backend/internal/models/requests"
 	"backend/internal/services"
 	"backend/pkg/cache"
 	configs "backend/pkg/config"
+	app_errors "backend/pkg/errors"
 	app_http "backend/pkg/http"
 	"backend/pkg/middlewares"
+	"backend/pkg/response"
 
 	"github.com/labstack/echo/v4"
 )
@@ -25,6 +28,7 @@ func NewUserController(userService *services.UserService,
 }
 func (c *UserController) RegisterRoute(r *echo.Group) {
 	r.GET("/users/me", c.Me, middlewares.ValidateTokenMiddleware(c.appConfig, c.redisCache))
+	r.PUT("/users/me", c.UpdateMe, middlewares.ValidateTokenMiddleware(c.appConfig, c.redisCache))
 }
 func (c *UserController) Me(ctx echo.Context) error {
 	id := c.CurrentUser(ctx).UserId
@@ -32,5 +36,27 @@ func (c *UserController) Me(ctx echo.Context) error {
 	if !result.IsSuccess {
 		return ctx.JSON(http.StatusBadRequest, result)
 	}
+	app_http.SetETag(ctx, result.Data.Version)
+	return ctx.JSON(http.StatusOK, result)
+}
+
+func (c *UserController) UpdateMe(ctx echo.Context) error {
+	var request requests.UpdateUserRequest
+	if err := ctx.Bind(&request); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.Failure(app_errors.NewGeneralError(app_errors.DataInvalid)))
+	}
+	version, err := app_http.IfMatchVersion(ctx)
+	if err != nil {
+		return err
+	}
+	id := c.CurrentUser(ctx).UserId
+	result := c.userService.UpdateUser(id, version, request, ctx.Request().Context())
+	if !result.IsSuccess {
+		if result.Code == int(app_errors.DataConflict) {
+			return ctx.JSON(http.StatusConflict, result)
+		}
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+	app_http.SetETag(ctx, result.Data.Version)
 	return ctx.JSON(http.StatusOK, result)
 }
@@ -0,0 +1,50 @@
+package entities
+
+import (
+	"backend/pkg/entity"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm/schema"
+)
+
+var (
+	Invitation_Pending  = "PENDING"
+	Invitation_Accepted = "ACCEPTED"
+	Invitation_Revoked  = "REVOKED"
+)
+
+type Organization struct {
+	entity.BaseAuditTrackingEntity
+	Name    string    `json:"name" gorm:"type:varchar(256);not null;"`
+	OwnerId uuid.UUID `json:"ownerId" gorm:"type:uuid;not null;index;"`
+}
+
+func (Organization) TableName(namer schema.Namer) string {
+	return entity.TableName(namer, AuthenticationSchema, "organizations")
+}
+
+type Team struct {
+	entity.BaseAuditTrackingEntity
+	OrganizationId uuid.UUID `json:"organizationId" gorm:"type:uuid;not null;index;"`
+	Name           string    `json:"name" gorm:"type:varchar(256);not null;"`
+}
+
+func (Team) TableName(namer schema.Namer) string {
+	return entity.TableName(namer, AuthenticationSchema, "teams")
+}
+
+type Invitation struct {
+	entity.BaseAuditTrackingEntity
+	OrganizationId      uuid.UUID     `json:"organizationId" gorm:"type:uuid;not null;index;"`
+	Email               string        `json:"email" gorm:"type:varchar(256);not null;index;"`
+	RoleId              uuid.UUID     `json:"roleId" gorm:"type:uuid;not null;"`
+	Status              string        `json:"status" gorm:"type:varchar(20);not null;"`
+	ExpiresAt           time.Time     `json:"expiresAt" gorm:"not null;"`
+	AcceptedBy          uuid.NullUUID `json:"acceptedBy,omitempty" gorm:"type:uuid;"`
+	AcceptedDateTimeUtc *time.Time    `json:"acceptedDateTimeUtc,omitempty" gorm:"null;"`
+}
+
+func (Invitation) TableName(namer schema.Namer) string {
+	return entity.TableName(namer, AuthenticationSchema, "invitations")
+}
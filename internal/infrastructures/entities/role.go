@@ -1,13 +1,34 @@
 package entities
 
-import "backend/pkg/entity"
+import (
+	"backend/pkg/entity"
+
+	"gorm.io/gorm/schema"
+)
+
+var (
+	Role_Admin      = "ADMIN"
+	Role_User       = "USER"
+	Role_OrgOwner   = "ORG_OWNER"
+	Role_OrgAdmin   = "ORG_ADMIN"
+	Role_OrgMember  = "ORG_MEMBER"
+	Role_TeamMember = "TEAM_MEMBER"
+)
+
+var (
+	RoleScope_Global       = "GLOBAL"
+	RoleScope_Organization = "ORGANIZATION"
+	RoleScope_Team         = "TEAM"
+)
 
 type Role struct {
 	entity.BaseAuditTrackingEntity
-	Name string `json:"name" gorm:"type:varchar(100);not null;"`
-	Code string `json:"code" gorm:"type:varchar(100);not null;"`
+	entity.WithHistory
+	Name  string `json:"name" gorm:"type:varchar(100);not null;"`
+	Code  string `json:"code" gorm:"type:varchar(100);not null;"`
+	Scope string `json:"scope" gorm:"type:varchar(20);not null;default:'GLOBAL';"`
 }
 
-func (Role) TableName() string {
-	return "authentication.roles"
+func (Role) TableName(namer schema.Namer) string {
+	return entity.TableName(namer, AuthenticationSchema, "roles")
 }
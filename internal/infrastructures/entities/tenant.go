@@ -0,0 +1,30 @@
+package entities
+
+import (
+	"backend/pkg/entity"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm/schema"
+)
+
+type Tenant struct {
+	entity.BaseAuditTrackingEntity
+	Name     string `json:"name" gorm:"type:varchar(256);not null;"`
+	Slug     string `json:"slug" gorm:"type:varchar(100);not null;uniqueIndex;"`
+	IsActive bool   `json:"isActive" gorm:"default:true;not null;"`
+}
+
+func (Tenant) TableName(namer schema.Namer) string {
+	return entity.TableName(namer, AuthenticationSchema, "tenants")
+}
+
+type TenantMembership struct {
+	entity.BaseAuditTrackingEntity
+	entity.Multitenant
+	UserId    uuid.UUID `json:"userId" gorm:"type:uuid;not null;index;"`
+	IsDefault bool      `json:"isDefault" gorm:"default:false;not null;"`
+}
+
+func (TenantMembership) TableName(namer schema.Namer) string {
+	return entity.TableName(namer, AuthenticationSchema, "tenant_memberships")
+}
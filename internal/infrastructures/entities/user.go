@@ -1,23 +1,32 @@
 package entities
 
 import (
+	"backend/pkg/encryption"
 	"backend/pkg/entity"
 	"fmt"
 	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
 )
 
+const AuthenticationSchema = "authentication"
+
 var (
 	Account_Internal = 0
 	Account_Client   = 100
 )
 
 type User struct {
-	entity.BaseAuditTrackingEntity
+	entity.BaseEntitySoftDelete
+	entity.Versioned
+	entity.WithHistory
 	FirstName         string     `json:"firstName" gorm:"type:varchar(100);not null;"`
 	LastName          string     `json:"lastName" gorm:"type:varchar(100);not null;"`
 	UserName          string     `json:"userName" gorm:"type:varchar(100);not null;"`
-	DateOfBirth       *time.Time `json:"dateOfBirth,omitempty" gorm:"null;"`
-	Email             string     `json:"email" gorm:"type:varchar(256);not null;"`
+	DateOfBirth       *time.Time `json:"dateOfBirth,omitempty" gorm:"type:text;null;serializer:encrypted;" history:"redact"`
+	Email             string     `json:"email" gorm:"type:text;not null;serializer:encrypted;" history:"redact"`
+	EmailIndex        string     `json:"-" gorm:"type:varchar(64);index;" history:"-"`
 	UserTypeID        int16      `json:"userTypeId" gorm:"type:smallint;default:1;not null;"`
 	Avatar            string     `json:"avatar,omitempty" gorm:"type:varchar(1024);"`
 	TwoFactorEnabled  bool       `json:"twoFactorEnabled" gorm:"default:false;not null;"`
@@ -25,14 +34,25 @@ type User struct {
 	LockoutEnabled    bool       `json:"lockoutEnabled" gorm:"default:false;not null;"`
 	AccessFailedCount int16      `json:"accessFailedCount" gorm:"type:smallint;default:0;not null;"`
 	EmailConfirm      bool       `json:"emailConfirm" gorm:"default:false;not null;"`
-	PasswordHash      string     `json:"passwordHash" gorm:"type:varchar(100);not null;"`
+	PasswordHash      string     `json:"passwordHash" gorm:"type:varchar(100);not null;" history:"redact"`
 	TimeZoneID        int16      `json:"timeZoneId,omitempty" gorm:"type:smallint;null;"`
+	Locale            string     `json:"locale" gorm:"type:varchar(16);not null;default:'';"`
 }
 
 func (u *User) FullName() string {
 	return fmt.Sprintf("%s %s", u.FirstName, u.LastName)
 }
 
-func (User) TableName() string {
-	return "authentication.users"
+func (User) TableName(namer schema.Namer) string {
+	return entity.TableName(namer, AuthenticationSchema, "users")
+}
+
+// BeforeSave keeps the blind index of the email in step with it, FindByEmail looks users up by the index
+func (u *User) BeforeSave(tx *gorm.DB) error {
+	index, err := encryption.BlindIndex(u.Email)
+	if err != nil {
+		return err
+	}
+	u.EmailIndex = index
+	return nil
 }
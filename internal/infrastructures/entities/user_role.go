@@ -4,14 +4,18 @@ import (
 	"backend/pkg/entity"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm/schema"
 )
 
+// UserRole grants a role to a user, ScopeId is the organization or team the grant applies to and is null for global roles
 type UserRole struct {
 	entity.BaseAuditTrackingEntity
-	UserId uuid.NullUUID `json:"userId,omitempty" gorm:"type:uuid;"`
-	RoleId uuid.NullUUID `json:"roleId,omitempty" gorm:"type:uuid;"`
+	entity.WithHistory
+	UserId  uuid.NullUUID `json:"userId,omitempty" gorm:"type:uuid;"`
+	RoleId  uuid.NullUUID `json:"roleId,omitempty" gorm:"type:uuid;"`
+	ScopeId uuid.NullUUID `json:"scopeId,omitempty" gorm:"type:uuid;index;"`
 }
 
-func (UserRole) TableName() string {
-	return "authentication.user_roles"
+func (UserRole) TableName(namer schema.Namer) string {
+	return entity.TableName(namer, AuthenticationSchema, "user_roles")
 }
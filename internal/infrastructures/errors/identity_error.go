@@ -14,6 +14,18 @@ const (
 	UserNotFound
 	OTPInvalid
 	EmailNotConfirmed
+	TenantNotFound
+	TenantSlugExisted
+	NotTenantMember
+	OrganizationNotFound
+	NotOrganizationMember
+	OrganizationPermissionDenied
+	MemberExisted
+	CanNotRemoveOwner
+	InvitationInvalid
+	InvitationExpired
+	TeamNotFound
+	InvitationMailFailed
 )
 
 func NewIdentityError(code IdentityErrorValue) app_errors.AppError {
@@ -44,4 +56,17 @@ var IdentityMessage = map[IdentityErrorValue]string{
 	EmailAlreadyConfirmed: "Email already confirmed",
 	OTPInvalid:            "OTP is invalid",
 	EmailNotConfirmed:     "Email is not confirmed",
+	TenantNotFound:        "Tenant is not exists",
+	TenantSlugExisted:     "Tenant slug is exists",
+	NotTenantMember:       "User is not a member of the tenant",
+
+	OrganizationNotFound:         "Organization is not exists",
+	NotOrganizationMember:        "User is not a member of the organization",
+	OrganizationPermissionDenied: "User is not allowed to manage the organization",
+	MemberExisted:                "User is already a member of the organization",
+	CanNotRemoveOwner:            "Owner can't be removed, transfer the ownership first",
+	InvitationInvalid:            "Invitation is invalid",
+	InvitationExpired:            "Invitation is expired",
+	TeamNotFound:                 "Team is not exists",
+	InvitationMailFailed:         "Invitation email can't be sent",
 }
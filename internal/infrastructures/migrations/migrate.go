@@ -2,16 +2,100 @@ package migrations
 
 import (
 	"backend/internal/infrastructures/entities"
+	configs "backend/pkg/config"
 	"backend/pkg/database"
+	"backend/pkg/entity"
+	"backend/pkg/environment"
+	"backend/pkg/mailer"
+	"context"
+	"embed"
+	"io/fs"
 )
 
+// SourceDir is where `migrate create` writes new files, relative to the repository root
+const SourceDir = "internal/infrastructures/migrations/sql"
+
+//go:embed sql/*.sql
+var files embed.FS
+
+func Source() fs.FS {
+	source, _ := fs.Sub(files, "sql")
+	return source
+}
+
+func NewMigrator(dbEngine database.DBEngine) *database.Migrator {
+	return database.NewMigrator(dbEngine, Source())
+}
+
 func GetModels() []any {
 	return []any{
 		&entities.User{},
 		&entities.Role{},
 		&entities.UserRole{},
+		&entities.Tenant{},
+		&entities.TenantMembership{},
+		&entities.Organization{},
+		&entities.Team{},
+		&entities.Invitation{},
+		&database.EntityHistory{},
+		&mailer.OutboxMessage{},
+		&mailer.Suppression{},
+	}
+}
+
+// Migrate runs on boot: versioned migrations when runOnStart is set, AutoMigrate only when opted in on development.
+// The SQL files target Postgres, so SQLite databases are always built with AutoMigrate.
+// Users still missing their email blind index are backfilled before the app serves, the boot fails when that fails.
+func Migrate(dbEngine database.DBEngine, appConfig *configs.AppConfig) error {
+	if err := migrateSchema(dbEngine, appConfig); err != nil {
+		return err
+	}
+	_, err := BackfillBlindIndexes(context.Background(), dbEngine)
+	return err
+}
+
+func migrateSchema(dbEngine database.DBEngine, appConfig *configs.AppConfig) error {
+	config := appConfig.Migration
+	if IsSqlite(dbEngine) {
+		return AutoMigrate(dbEngine)
+	}
+	if config.RunOnStart {
+		if _, err := NewMigrator(dbEngine).Up(context.Background()); err != nil {
+			return err
+		}
+	}
+	if config.AutoMigrate && environment.GetEnvironment().IsDevelopment() {
+		return AutoMigrate(dbEngine)
+	}
+	return nil
+}
+
+// AutoMigrate creates the tables from the entities and seeds the default roles
+func AutoMigrate(dbEngine database.DBEngine) error {
+	if err := dbEngine.Migrate(GetModels()...); err != nil {
+		return err
 	}
+	return Seed(dbEngine)
 }
-func Migrate(dbEngine database.DBEngine) error {
-	return dbEngine.Migrate(GetModels()...)
+
+func Seed(dbEngine database.DBEngine) error {
+	roles := []entities.Role{
+		{BaseAuditTrackingEntity: entity.NewSQLModel(), Name: "Administrator", Code: entities.Role_Admin, Scope: entities.RoleScope_Global},
+		{BaseAuditTrackingEntity: entity.NewSQLModel(), Name: "User", Code: entities.Role_User, Scope: entities.RoleScope_Global},
+		{BaseAuditTrackingEntity: entity.NewSQLModel(), Name: "Organization Owner", Code: entities.Role_OrgOwner, Scope: entities.RoleScope_Organization},
+		{BaseAuditTrackingEntity: entity.NewSQLModel(), Name: "Organization Admin", Code: entities.Role_OrgAdmin, Scope: entities.RoleScope_Organization},
+		{BaseAuditTrackingEntity: entity.NewSQLModel(), Name: "Organization Member", Code: entities.Role_OrgMember, Scope: entities.RoleScope_Organization},
+		{BaseAuditTrackingEntity: entity.NewSQLModel(), Name: "Team Member", Code: entities.Role_TeamMember, Scope: entities.RoleScope_Team},
+	}
+	db := dbEngine.GetDatabase()
+	for _, role := range roles {
+		if err := db.Where(entities.Role{Code: role.Code}).FirstOrCreate(&role).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func IsSqlite(dbEngine database.DBEngine) bool {
+	return dbEngine.GetDatabase().Dialector.Name() == "sqlite"
 }
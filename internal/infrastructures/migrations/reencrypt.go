@@ -0,0 +1,69 @@
+package migrations
+
+import (
+	"context"
+
+	"backend/internal/infrastructures/entities"
+	"backend/pkg/database"
+	"backend/pkg/encryption"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const _reencryptBatchSize = 500
+
+// Reencrypt rewrites the encrypted columns of every user with the active key and refreshes their blind index.
+// Run it after rotating keys.
+func Reencrypt(ctx context.Context, dbEngine database.DBEngine) (int, error) {
+	return reencrypt(dbEngine.GetDatabase().WithContext(ctx), false)
+}
+
+// BackfillBlindIndexes seals the users written before their columns were encrypted and fills their email blind index.
+// FindByEmail can not see a user without one, and the unique index skips them, so it runs right after the migrations.
+func BackfillBlindIndexes(ctx context.Context, dbEngine database.DBEngine) (int, error) {
+	db := dbEngine.GetDatabase().WithContext(ctx)
+	if !db.Migrator().HasColumn(&entities.User{}, "EmailIndex") {
+		return 0, nil
+	}
+	return reencrypt(db, true)
+}
+
+// reencrypt walks the users by id, onlyUnindexed narrows it down to the ones without a blind index
+func reencrypt(db *gorm.DB, onlyUnindexed bool) (int, error) {
+	total := 0
+	lastId := uuid.Nil
+	for {
+		var users []entities.User
+		query := db.Where("id > ?", lastId)
+		if onlyUnindexed {
+			query = query.Where("email_index IS NULL")
+		}
+		if err := query.Order("id").Limit(_reencryptBatchSize).Find(&users).Error; err != nil {
+			return total, err
+		}
+		if len(users) == 0 {
+			return total, nil
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			for i := range users {
+				user := &users[i]
+				index, err := encryption.BlindIndex(user.Email)
+				if err != nil {
+					return err
+				}
+				user.EmailIndex = index
+				if err := tx.Model(user).Select("email", "email_index", "date_of_birth").UpdateColumns(user).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return total, err
+		}
+		total += len(users)
+		lastId = users[len(users)-1].Id
+	}
+}
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS authentication.user_roles;
+DROP TABLE IF EXISTS authentication.roles;
+DROP TABLE IF EXISTS authentication.users;
+DROP SCHEMA IF EXISTS authentication;
@@ -0,0 +1,60 @@
+CREATE SCHEMA IF NOT EXISTS authentication;
+
+CREATE TABLE IF NOT EXISTS authentication.users (
+    id uuid PRIMARY KEY,
+    created_date_time_utc timestamptz NOT NULL,
+    updated_date_time_utc timestamptz NOT NULL,
+    created_by uuid NULL,
+    updated_by uuid NULL,
+    first_name varchar(100) NOT NULL,
+    last_name varchar(100) NOT NULL,
+    user_name varchar(100) NOT NULL,
+    date_of_birth timestamptz NULL,
+    email varchar(256) NOT NULL,
+    user_type_id smallint NOT NULL DEFAULT 1,
+    avatar varchar(1024) NULL,
+    two_factor_enabled boolean NOT NULL DEFAULT false,
+    lockout_end timestamptz NULL,
+    lockout_enabled boolean NOT NULL DEFAULT false,
+    access_failed_count smallint NOT NULL DEFAULT 0,
+    email_confirm boolean NOT NULL DEFAULT false,
+    password_hash varchar(100) NOT NULL,
+    time_zone_id smallint NULL
+);
+
+-- databases created by AutoMigrate before soft delete and row versioning existed
+ALTER TABLE authentication.users ADD COLUMN IF NOT EXISTS is_deleted boolean NOT NULL DEFAULT false;
+ALTER TABLE authentication.users ADD COLUMN IF NOT EXISTS deleted_date_time_utc timestamptz NULL;
+ALTER TABLE authentication.users ADD COLUMN IF NOT EXISTS version bigint NOT NULL DEFAULT 1;
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_users_email ON authentication.users (email) WHERE is_deleted = false;
+
+CREATE TABLE IF NOT EXISTS authentication.roles (
+    id uuid PRIMARY KEY,
+    created_date_time_utc timestamptz NOT NULL,
+    updated_date_time_utc timestamptz NOT NULL,
+    created_by uuid NULL,
+    updated_by uuid NULL,
+    name varchar(100) NOT NULL,
+    code varchar(100) NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_roles_code ON authentication.roles (code);
+
+CREATE TABLE IF NOT EXISTS authentication.user_roles (
+    id uuid PRIMARY KEY,
+    created_date_time_utc timestamptz NOT NULL,
+    updated_date_time_utc timestamptz NOT NULL,
+    created_by uuid NULL,
+    updated_by uuid NULL,
+    user_id uuid NULL REFERENCES authentication.users (id),
+    role_id uuid NULL REFERENCES authentication.roles (id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_user_roles_user_role ON authentication.user_roles (user_id, role_id);
+
+INSERT INTO authentication.roles (id, created_date_time_utc, updated_date_time_utc, name, code)
+VALUES
+    (gen_random_uuid(), now(), now(), 'Administrator', 'ADMIN'),
+    (gen_random_uuid(), now(), now(), 'User', 'USER')
+ON CONFLICT (code) DO NOTHING;
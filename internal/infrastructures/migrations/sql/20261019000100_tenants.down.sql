@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS authentication.tenant_memberships;
+DROP TABLE IF EXISTS authentication.tenants;
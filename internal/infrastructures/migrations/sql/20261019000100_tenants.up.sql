@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS authentication.tenants (
+    id uuid PRIMARY KEY,
+    created_date_time_utc timestamptz NOT NULL,
+    updated_date_time_utc timestamptz NOT NULL,
+    created_by uuid NULL,
+    updated_by uuid NULL,
+    name varchar(256) NOT NULL,
+    slug varchar(100) NOT NULL,
+    is_active boolean NOT NULL DEFAULT true
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_tenants_slug ON authentication.tenants (slug);
+
+CREATE TABLE IF NOT EXISTS authentication.tenant_memberships (
+    id uuid PRIMARY KEY,
+    created_date_time_utc timestamptz NOT NULL,
+    updated_date_time_utc timestamptz NOT NULL,
+    created_by uuid NULL,
+    updated_by uuid NULL,
+    tenant_id uuid NOT NULL REFERENCES authentication.tenants (id),
+    user_id uuid NOT NULL REFERENCES authentication.users (id),
+    is_default boolean NOT NULL DEFAULT false
+);
+
+CREATE INDEX IF NOT EXISTS idx_tenant_memberships_tenant_id ON authentication.tenant_memberships (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_tenant_memberships_user_id ON authentication.tenant_memberships (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS ux_tenant_memberships_tenant_user ON authentication.tenant_memberships (tenant_id, user_id);
@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS authentication.invitations;
+DROP TABLE IF EXISTS authentication.teams;
+DROP TABLE IF EXISTS authentication.organizations;
+
+DELETE FROM authentication.user_roles WHERE scope_id IS NOT NULL;
+DELETE FROM authentication.roles WHERE code IN ('ORG_OWNER', 'ORG_ADMIN', 'ORG_MEMBER', 'TEAM_MEMBER');
+
+DROP INDEX IF EXISTS authentication.idx_user_roles_scope_id;
+DROP INDEX IF EXISTS authentication.ux_user_roles_user_role_scope;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_user_roles_user_role ON authentication.user_roles (user_id, role_id);
+
+ALTER TABLE authentication.user_roles DROP COLUMN IF EXISTS scope_id;
+ALTER TABLE authentication.roles DROP COLUMN IF EXISTS scope;
@@ -0,0 +1,57 @@
+ALTER TABLE authentication.roles ADD COLUMN IF NOT EXISTS scope varchar(20) NOT NULL DEFAULT 'GLOBAL';
+ALTER TABLE authentication.user_roles ADD COLUMN IF NOT EXISTS scope_id uuid NULL;
+
+DROP INDEX IF EXISTS authentication.ux_user_roles_user_role;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_user_roles_user_role_scope
+    ON authentication.user_roles (user_id, role_id, COALESCE(scope_id, '00000000-0000-0000-0000-000000000000'::uuid));
+CREATE INDEX IF NOT EXISTS idx_user_roles_scope_id ON authentication.user_roles (scope_id);
+
+CREATE TABLE IF NOT EXISTS authentication.organizations (
+    id uuid PRIMARY KEY,
+    created_date_time_utc timestamptz NOT NULL,
+    updated_date_time_utc timestamptz NOT NULL,
+    created_by uuid NULL,
+    updated_by uuid NULL,
+    name varchar(256) NOT NULL,
+    owner_id uuid NOT NULL REFERENCES authentication.users (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_organizations_owner_id ON authentication.organizations (owner_id);
+
+CREATE TABLE IF NOT EXISTS authentication.teams (
+    id uuid PRIMARY KEY,
+    created_date_time_utc timestamptz NOT NULL,
+    updated_date_time_utc timestamptz NOT NULL,
+    created_by uuid NULL,
+    updated_by uuid NULL,
+    organization_id uuid NOT NULL REFERENCES authentication.organizations (id),
+    name varchar(256) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_teams_organization_id ON authentication.teams (organization_id);
+
+CREATE TABLE IF NOT EXISTS authentication.invitations (
+    id uuid PRIMARY KEY,
+    created_date_time_utc timestamptz NOT NULL,
+    updated_date_time_utc timestamptz NOT NULL,
+    created_by uuid NULL,
+    updated_by uuid NULL,
+    organization_id uuid NOT NULL REFERENCES authentication.organizations (id),
+    email varchar(256) NOT NULL,
+    role_id uuid NOT NULL REFERENCES authentication.roles (id),
+    status varchar(20) NOT NULL,
+    expires_at timestamptz NOT NULL,
+    accepted_by uuid NULL,
+    accepted_date_time_utc timestamptz NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_invitations_organization_id ON authentication.invitations (organization_id);
+CREATE INDEX IF NOT EXISTS idx_invitations_email ON authentication.invitations (email);
+
+INSERT INTO authentication.roles (id, created_date_time_utc, updated_date_time_utc, name, code, scope)
+VALUES
+    (gen_random_uuid(), now(), now(), 'Organization Owner', 'ORG_OWNER', 'ORGANIZATION'),
+    (gen_random_uuid(), now(), now(), 'Organization Admin', 'ORG_ADMIN', 'ORGANIZATION'),
+    (gen_random_uuid(), now(), now(), 'Organization Member', 'ORG_MEMBER', 'ORGANIZATION'),
+    (gen_random_uuid(), now(), now(), 'Team Member', 'TEAM_MEMBER', 'TEAM')
+ON CONFLICT (code) DO NOTHING;
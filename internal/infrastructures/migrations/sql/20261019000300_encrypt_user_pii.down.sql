@@ -0,0 +1,8 @@
+-- sealed values can not be opened in SQL, only rows still holding plain JSON roll back
+DROP INDEX IF EXISTS authentication.ux_users_email_index;
+ALTER TABLE authentication.users DROP COLUMN IF EXISTS email_index;
+
+ALTER TABLE authentication.users ALTER COLUMN date_of_birth TYPE timestamptz USING (date_of_birth::json #>> '{}')::timestamptz;
+ALTER TABLE authentication.users ALTER COLUMN email TYPE varchar(256) USING email::json #>> '{}';
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_users_email ON authentication.users (email) WHERE is_deleted = false;
@@ -0,0 +1,8 @@
+-- existing values become plain JSON, which the encrypted serializer still reads;
+-- migrate up and Migrate on boot seal them and fill the email blind index right after the migrations
+ALTER TABLE authentication.users ALTER COLUMN email TYPE text USING to_json(email)::text;
+ALTER TABLE authentication.users ALTER COLUMN date_of_birth TYPE text USING to_json(date_of_birth)::text;
+ALTER TABLE authentication.users ADD COLUMN IF NOT EXISTS email_index varchar(64) NULL;
+
+DROP INDEX IF EXISTS authentication.ux_users_email;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_users_email_index ON authentication.users (email_index) WHERE is_deleted = false;
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS entity_history (
+    id uuid PRIMARY KEY,
+    entity_type varchar(100) NOT NULL,
+    entity_id varchar(64) NOT NULL,
+    action varchar(20) NOT NULL,
+    changes text NULL,
+    actor_id uuid NULL,
+    occurred_at timestamptz NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_entity_history_entity ON entity_history (entity_id, entity_type);
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS mail_outbox (
+    id uuid PRIMARY KEY,
+    idempotency_key varchar(256) NOT NULL,
+    recipient text NOT NULL,
+    subject varchar(512) NOT NULL,
+    payload text NOT NULL,
+    status varchar(20) NOT NULL,
+    attempts integer NOT NULL DEFAULT 0,
+    next_attempt_at timestamptz NOT NULL,
+    locked_until timestamptz NULL,
+    last_error text NULL,
+    sent_at timestamptz NULL,
+    created_date_time_utc timestamptz NOT NULL,
+    updated_date_time_utc timestamptz NOT NULL,
+    created_by uuid NULL,
+    updated_by uuid NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_mail_outbox_idempotency_key ON mail_outbox (idempotency_key);
+CREATE INDEX IF NOT EXISTS idx_mail_outbox_due ON mail_outbox (status, next_attempt_at);
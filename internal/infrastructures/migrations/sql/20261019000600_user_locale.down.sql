@@ -0,0 +1 @@
+ALTER TABLE authentication.users DROP COLUMN IF EXISTS locale;
@@ -0,0 +1,2 @@
+-- preferred language of the mails, empty falls back to the default locale
+ALTER TABLE authentication.users ADD COLUMN IF NOT EXISTS locale varchar(16) NOT NULL DEFAULT '';
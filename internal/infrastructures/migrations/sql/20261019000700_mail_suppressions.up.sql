@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS mail_suppressions (
+    id uuid PRIMARY KEY,
+    address_index varchar(64) NOT NULL,
+    address text NOT NULL,
+    reason varchar(20) NOT NULL,
+    provider varchar(20) NOT NULL,
+    detail text NULL,
+    created_date_time_utc timestamptz NOT NULL,
+    updated_date_time_utc timestamptz NOT NULL,
+    created_by uuid NULL,
+    updated_by uuid NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_mail_suppressions_address_index ON mail_suppressions (address_index);
@@ -0,0 +1 @@
+ALTER TABLE mail_outbox ADD COLUMN IF NOT EXISTS lease_id uuid NULL;
@@ -3,6 +3,7 @@ package repositories
 import (
 	"backend/internal/infrastructures/entities"
 	"backend/pkg/database"
+	"backend/pkg/encryption"
 	"context"
 
 	"github.com/google/uuid"
@@ -24,7 +25,11 @@ func NewUserRepository(dbEngine database.DBEngine) UserRepository {
 }
 
 func (r *userRepository) FindByEmail(ctx context.Context, email string) (*entities.User, error) {
-	user, err := r.First(&entities.User{Email: email}, ctx)
+	index, err := encryption.BlindIndex(email)
+	if err != nil {
+		return nil, err
+	}
+	user, err := r.First(&entities.User{EmailIndex: index}, ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,36 @@
+package repositories
+
+import (
+	"backend/pkg/database"
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type EntityHistoryRepository interface {
+	database.RepositoryBase[database.EntityHistory, uuid.UUID]
+	// FindByEntity lists the changes of an entity, newest first, entityType narrows it down when set
+	FindByEntity(ctx context.Context, entityType string, entityId string) ([]database.EntityHistory, error)
+}
+type entityHistoryRepository struct {
+	database.Repository[database.EntityHistory, uuid.UUID]
+}
+
+func NewEntityHistoryRepository(dbEngine database.DBEngine) EntityHistoryRepository {
+	DbContext := dbEngine.GetDatabase()
+	return &entityHistoryRepository{
+		Repository: *database.NewRepository[database.EntityHistory, uuid.UUID](DbContext),
+	}
+}
+
+func (r *entityHistoryRepository) FindByEntity(ctx context.Context, entityType string, entityId string) ([]database.EntityHistory, error) {
+	var history []database.EntityHistory
+	query := r.DB(ctx).Where("entity_id = ?", entityId)
+	if entityType != "" {
+		query = query.Where("entity_type = ?", entityType)
+	}
+	if err := query.Order("occurred_at desc").Find(&history).Error; err != nil {
+		return nil, err
+	}
+	return history, nil
+}
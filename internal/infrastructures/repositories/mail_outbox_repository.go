@@ -0,0 +1,138 @@
+package repositories
+
+import (
+	"backend/pkg/database"
+	"backend/pkg/mailer"
+	"backend/pkg/utils"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type MailOutboxRepository interface {
+	database.RepositoryBase[mailer.OutboxMessage, uuid.UUID]
+	mailer.OutboxStore
+	// Page reads a page of the outbox, newest first unless the request orders it otherwise, status narrows it down when set
+	Page(ctx context.Context, status string, page *utils.CursorPagination) ([]mailer.OutboxMessage, *utils.CursorPage, error)
+}
+type mailOutboxRepository struct {
+	database.Repository[mailer.OutboxMessage, uuid.UUID]
+}
+
+func NewMailOutboxRepository(dbEngine database.DBEngine) MailOutboxRepository {
+	DbContext := dbEngine.GetDatabase()
+	return &mailOutboxRepository{
+		Repository: *database.NewRepository[mailer.OutboxMessage, uuid.UUID](DbContext),
+	}
+}
+
+func (r *mailOutboxRepository) Enqueue(ctx context.Context, message *mailer.OutboxMessage) (bool, error) {
+	result := r.DB(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "idempotency_key"}},
+		DoNothing: true,
+	}).Create(message)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Claim flips each due message to SENDING with a conditional update, a message claimed by another worker meanwhile is skipped
+func (r *mailOutboxRepository) Claim(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]mailer.OutboxMessage, error) {
+	var due []mailer.OutboxMessage
+	if err := r.due(r.DB(ctx), now).Order("next_attempt_at").Limit(limit).Find(&due).Error; err != nil {
+		return nil, err
+	}
+
+	claimed := make([]mailer.OutboxMessage, 0, len(due))
+	for _, message := range due {
+		leaseId := uuid.NullUUID{UUID: uuid.New(), Valid: true}
+		result := r.due(r.DB(ctx).Model(&mailer.OutboxMessage{}), now).
+			Where("id = ?", message.Id).
+			Updates(map[string]any{"status": mailer.OutboxSending, "locked_until": leaseUntil, "lease_id": leaseId})
+		if result.Error != nil {
+			return claimed, result.Error
+		}
+		if result.RowsAffected == 1 {
+			message.Status = mailer.OutboxSending
+			message.LockedUntil = &leaseUntil
+			message.LeaseId = leaseId
+			claimed = append(claimed, message)
+		}
+	}
+	return claimed, nil
+}
+
+func (r *mailOutboxRepository) due(db *gorm.DB, now time.Time) *gorm.DB {
+	return db.Where("(status = ? AND next_attempt_at <= ?) OR (status = ? AND locked_until < ?)",
+		mailer.OutboxPending, now, mailer.OutboxSending, now)
+}
+
+func (r *mailOutboxRepository) Renew(ctx context.Context, message *mailer.OutboxMessage, leaseUntil time.Time) (bool, error) {
+	result := r.DB(ctx).Model(&mailer.OutboxMessage{}).
+		Where("id = ? AND status = ? AND lease_id = ?", message.Id, mailer.OutboxSending, message.LeaseId).
+		Update("locked_until", leaseUntil)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 1 {
+		message.LockedUntil = &leaseUntil
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Save releases the lease the message holds along with storing the outcome
+func (r *mailOutboxRepository) Save(ctx context.Context, message *mailer.OutboxMessage) error {
+	lease := message.LeaseId
+	message.LeaseId = uuid.NullUUID{}
+	query := r.DB(ctx).Model(message)
+	if lease.Valid {
+		query = query.Where("lease_id = ?", lease)
+	}
+	result := query.Select("status", "attempts", "next_attempt_at", "locked_until", "lease_id", "last_error", "sent_at", "updated_date_time_utc", "updated_by").
+		Updates(message)
+	if result.Error != nil {
+		return result.Error
+	}
+	if lease.Valid && result.RowsAffected == 0 {
+		return mailer.ErrLeaseLost
+	}
+	return nil
+}
+
+func (r *mailOutboxRepository) Find(ctx context.Context, id uuid.UUID) (*mailer.OutboxMessage, error) {
+	var message mailer.OutboxMessage
+	if err := r.DB(ctx).Where("id = ?", id).First(&message).Error; err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+func (r *mailOutboxRepository) PurgeSent(ctx context.Context, before time.Time, limit int) (int64, error) {
+	expired := r.DB(ctx).Model(&mailer.OutboxMessage{}).Select("id").
+		Where("status = ? AND sent_at < ?", mailer.OutboxSent, before).Limit(limit)
+	result := r.DB(ctx).Where("id IN (?)", expired).Delete(&mailer.OutboxMessage{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *mailOutboxRepository) Page(ctx context.Context, status string, page *utils.CursorPagination) ([]mailer.OutboxMessage, *utils.CursorPage, error) {
+	// defaults go on a copy, the caller's request stays as it was
+	query := *page
+	if query.SortKey == "" {
+		query.SortKey = "created_date_time_utc"
+		query.Desc = true
+	}
+	messages, cursors, err := r.SeekPage(&query, ctx, func(db *gorm.DB) *gorm.DB {
+		if status == "" {
+			return db
+		}
+		return db.Where("status = ?", status)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return *messages, cursors, nil
+}
@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"backend/pkg/database"
+	"backend/pkg/encryption"
+	"backend/pkg/mailer"
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm/clause"
+)
+
+type MailSuppressionRepository interface {
+	database.RepositoryBase[mailer.Suppression, uuid.UUID]
+	mailer.SuppressionStore
+}
+type mailSuppressionRepository struct {
+	database.Repository[mailer.Suppression, uuid.UUID]
+}
+
+func NewMailSuppressionRepository(dbEngine database.DBEngine) MailSuppressionRepository {
+	DbContext := dbEngine.GetDatabase()
+	return &mailSuppressionRepository{
+		Repository: *database.NewRepository[mailer.Suppression, uuid.UUID](DbContext),
+	}
+}
+
+func (r *mailSuppressionRepository) Suppress(ctx context.Context, suppression *mailer.Suppression) error {
+	return r.DB(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "address_index"}},
+		DoNothing: true,
+	}).Create(suppression).Error
+}
+
+// Suppressed matches the addresses by their blind index, the encrypted column cannot be queried
+func (r *mailSuppressionRepository) Suppressed(ctx context.Context, addresses []string) (map[string]bool, error) {
+	byIndex := make(map[string]string, len(addresses))
+	for _, address := range addresses {
+		index, err := encryption.BlindIndex(address)
+		if err != nil {
+			return nil, err
+		}
+		byIndex[index] = address
+	}
+	indexes := make([]string, 0, len(byIndex))
+	for index := range byIndex {
+		indexes = append(indexes, index)
+	}
+
+	var found []string
+	if err := r.DB(ctx).Model(&mailer.Suppression{}).Where("address_index IN ?", indexes).Pluck("address_index", &found).Error; err != nil {
+		return nil, err
+	}
+	suppressed := make(map[string]bool, len(found))
+	for _, index := range found {
+		suppressed[byIndex[index]] = true
+	}
+	return suppressed, nil
+}
@@ -1,9 +1,23 @@
 package repositories
 
-import "go.uber.org/fx"
+import (
+	"backend/pkg/mailer"
+
+	"go.uber.org/fx"
+)
 
 var Module = fx.Module("repositories",
 	fx.Provide(
 		NewUserRepository,
+		NewRoleRepository,
+		NewUserRoleRepository,
+		NewTenantRepository,
+		NewTenantMembershipRepository,
+		NewOrganizationRepository,
+		NewTeamRepository,
+		NewInvitationRepository,
+		NewEntityHistoryRepository,
+		fx.Annotate(NewMailOutboxRepository, fx.As(new(mailer.OutboxStore), new(MailOutboxRepository))),
+		fx.Annotate(NewMailSuppressionRepository, fx.As(new(mailer.SuppressionStore))),
 	),
 )
@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"backend/internal/infrastructures/entities"
+	"backend/pkg/database"
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type OrganizationRepository interface {
+	database.RepositoryBase[entities.Organization, uuid.UUID]
+	// FindByMember lists the organizations a user holds a role in
+	FindByMember(ctx context.Context, userId uuid.UUID) ([]entities.Organization, error)
+}
+type organizationRepository struct {
+	database.Repository[entities.Organization, uuid.UUID]
+}
+
+func NewOrganizationRepository(dbEngine database.DBEngine) OrganizationRepository {
+	DbContext := dbEngine.GetDatabase()
+	return &organizationRepository{
+		Repository: *database.NewRepository[entities.Organization, uuid.UUID](DbContext),
+	}
+}
+
+func (r *organizationRepository) FindByMember(ctx context.Context, userId uuid.UUID) ([]entities.Organization, error) {
+	var organizations []entities.Organization
+	scopes := r.DB(ctx).Model(&entities.UserRole{}).Select("scope_id").Where("user_id = ?", userId)
+	if err := r.DB(ctx).Where("id IN (?)", scopes).Order("name").Find(&organizations).Error; err != nil {
+		return nil, err
+	}
+	return organizations, nil
+}
+
+type TeamRepository interface {
+	database.RepositoryBase[entities.Team, uuid.UUID]
+	FindByOrganization(ctx context.Context, organizationId uuid.UUID) ([]entities.Team, error)
+}
+type teamRepository struct {
+	database.Repository[entities.Team, uuid.UUID]
+}
+
+func NewTeamRepository(dbEngine database.DBEngine) TeamRepository {
+	DbContext := dbEngine.GetDatabase()
+	return &teamRepository{
+		Repository: *database.NewRepository[entities.Team, uuid.UUID](DbContext),
+	}
+}
+
+func (r *teamRepository) FindByOrganization(ctx context.Context, organizationId uuid.UUID) ([]entities.Team, error) {
+	var teams []entities.Team
+	if err := r.DB(ctx).Where("organization_id = ?", organizationId).Order("name").Find(&teams).Error; err != nil {
+		return nil, err
+	}
+	return teams, nil
+}
+
+type InvitationRepository interface {
+	database.RepositoryBase[entities.Invitation, uuid.UUID]
+	// FindPending lists the invitations of an email to an organization that are not accepted or revoked yet
+	FindPending(ctx context.Context, organizationId uuid.UUID, email string) ([]entities.Invitation, error)
+}
+type invitationRepository struct {
+	database.Repository[entities.Invitation, uuid.UUID]
+}
+
+func NewInvitationRepository(dbEngine database.DBEngine) InvitationRepository {
+	DbContext := dbEngine.GetDatabase()
+	return &invitationRepository{
+		Repository: *database.NewRepository[entities.Invitation, uuid.UUID](DbContext),
+	}
+}
+
+func (r *invitationRepository) FindPending(ctx context.Context, organizationId uuid.UUID, email string) ([]entities.Invitation, error) {
+	var invitations []entities.Invitation
+	err := r.DB(ctx).
+		Where("organization_id = ? AND email = ? AND status = ?", organizationId, email, entities.Invitation_Pending).
+		Find(&invitations).Error
+	if err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
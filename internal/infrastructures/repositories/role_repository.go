@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"backend/internal/infrastructures/entities"
+	"backend/pkg/database"
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type RoleRepository interface {
+	database.RepositoryBase[entities.Role, uuid.UUID]
+	FindByCode(ctx context.Context, code string) (*entities.Role, error)
+}
+type roleRepository struct {
+	database.Repository[entities.Role, uuid.UUID]
+}
+
+func NewRoleRepository(dbEngine database.DBEngine) RoleRepository {
+	DbContext := dbEngine.GetDatabase()
+	return &roleRepository{
+		Repository: *database.NewRepository[entities.Role, uuid.UUID](DbContext),
+	}
+}
+
+func (r *roleRepository) FindByCode(ctx context.Context, code string) (*entities.Role, error) {
+	var role entities.Role
+	if err := r.DB(ctx).Where("code = ?", code).First(&role).Error; err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+type UserRoleRepository interface {
+	database.RepositoryBase[entities.UserRole, uuid.UUID]
+	// FindByScope lists the role grants of an organization or team
+	FindByScope(ctx context.Context, scopeId uuid.UUID) ([]entities.UserRole, error)
+	// FindUserScoped lists the role grants of a user within an organization or team
+	FindUserScoped(ctx context.Context, userId uuid.UUID, scopeIds ...uuid.UUID) ([]entities.UserRole, error)
+	// HasGlobalRole reports whether the user is granted the role outside of any organization or team
+	HasGlobalRole(ctx context.Context, userId uuid.UUID, code string) (bool, error)
+}
+type userRoleRepository struct {
+	database.Repository[entities.UserRole, uuid.UUID]
+}
+
+func NewUserRoleRepository(dbEngine database.DBEngine) UserRoleRepository {
+	DbContext := dbEngine.GetDatabase()
+	return &userRoleRepository{
+		Repository: *database.NewRepository[entities.UserRole, uuid.UUID](DbContext),
+	}
+}
+
+func (r *userRoleRepository) FindByScope(ctx context.Context, scopeId uuid.UUID) ([]entities.UserRole, error) {
+	var userRoles []entities.UserRole
+	if err := r.DB(ctx).Where("scope_id = ?", scopeId).Find(&userRoles).Error; err != nil {
+		return nil, err
+	}
+	return userRoles, nil
+}
+
+func (r *userRoleRepository) FindUserScoped(ctx context.Context, userId uuid.UUID, scopeIds ...uuid.UUID) ([]entities.UserRole, error) {
+	var userRoles []entities.UserRole
+	if len(scopeIds) == 0 {
+		return userRoles, nil
+	}
+	if err := r.DB(ctx).Where("user_id = ? AND scope_id IN ?", userId, scopeIds).Find(&userRoles).Error; err != nil {
+		return nil, err
+	}
+	return userRoles, nil
+}
+
+func (r *userRoleRepository) HasGlobalRole(ctx context.Context, userId uuid.UUID, code string) (bool, error) {
+	var count int64
+	roles := r.DB(ctx).Model(&entities.Role{}).Select("id").Where("code = ?", code)
+	err := r.DB(ctx).Model(&entities.UserRole{}).
+		Where("user_id = ? AND scope_id IS NULL AND role_id IN (?)", userId, roles).
+		Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"backend/internal/infrastructures/entities"
+	"backend/pkg/database"
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type TenantRepository interface {
+	database.RepositoryBase[entities.Tenant, uuid.UUID]
+	FindBySlug(ctx context.Context, slug string) (*entities.Tenant, error)
+}
+type tenantRepository struct {
+	database.Repository[entities.Tenant, uuid.UUID]
+}
+
+func NewTenantRepository(dbEngine database.DBEngine) TenantRepository {
+	DbContext := dbEngine.GetDatabase()
+	return &tenantRepository{
+		Repository: *database.NewRepository[entities.Tenant, uuid.UUID](DbContext),
+	}
+}
+
+func (r *tenantRepository) FindBySlug(ctx context.Context, slug string) (*entities.Tenant, error) {
+	var tenant entities.Tenant
+	if err := r.DB(ctx).Where("slug = ?", slug).First(&tenant).Error; err != nil {
+		return nil, err
+	}
+	return &tenant, nil
+}
+
+type TenantMembershipRepository interface {
+	database.RepositoryBase[entities.TenantMembership, uuid.UUID]
+	// FindByUser lists the memberships of a user across every tenant
+	FindByUser(ctx context.Context, userId uuid.UUID) ([]entities.TenantMembership, error)
+	// FindMembership returns the membership of a user in the tenant of ctx
+	FindMembership(ctx context.Context, userId uuid.UUID) (*entities.TenantMembership, error)
+}
+type tenantMembershipRepository struct {
+	database.Repository[entities.TenantMembership, uuid.UUID]
+}
+
+func NewTenantMembershipRepository(dbEngine database.DBEngine) TenantMembershipRepository {
+	DbContext := dbEngine.GetDatabase()
+	return &tenantMembershipRepository{
+		Repository: *database.NewRepository[entities.TenantMembership, uuid.UUID](DbContext),
+	}
+}
+
+func (r *tenantMembershipRepository) FindByUser(ctx context.Context, userId uuid.UUID) ([]entities.TenantMembership, error) {
+	memberships, err := r.Where(&entities.TenantMembership{UserId: userId}, database.WithAllTenants(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return *memberships, nil
+}
+
+func (r *tenantMembershipRepository) FindMembership(ctx context.Context, userId uuid.UUID) (*entities.TenantMembership, error) {
+	return r.First(&entities.TenantMembership{UserId: userId}, ctx)
+}
@@ -5,6 +5,7 @@ type CreateUserRequest struct {
 	Password  string
 	FirstName string
 	LastName  string
+	Locale    string
 }
 type LoginRequest struct {
 	Email    string
@@ -0,0 +1,27 @@
+package requests
+
+import "github.com/google/uuid"
+
+type CreateOrganizationRequest struct {
+	Name string
+}
+type InviteMemberRequest struct {
+	Email string
+	Role  string
+}
+type AcceptInvitationRequest struct {
+	Token     string
+	Password  string
+	FirstName string
+	LastName  string
+	Locale    string
+}
+type TransferOwnershipRequest struct {
+	UserId uuid.UUID
+}
+type CreateTeamRequest struct {
+	Name string
+}
+type AddTeamMemberRequest struct {
+	UserId uuid.UUID
+}
@@ -0,0 +1,6 @@
+package requests
+
+type CreateTenantRequest struct {
+	Name string
+	Slug string
+}
@@ -0,0 +1,11 @@
+package requests
+
+import "time"
+
+type UpdateUserRequest struct {
+	FirstName   string
+	LastName    string
+	Avatar      string
+	DateOfBirth *time.Time
+	Locale      string
+}
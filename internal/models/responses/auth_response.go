@@ -19,4 +19,6 @@ type UserResponse struct {
 	FullName    string     `json:"fullName"`
 	Avatar      string     `json:"avatar,omitempty"`
 	DateOfBirth *time.Time `json:"dateOfBirth,omitempty"`
+	Locale      string     `json:"locale,omitempty"`
+	Version     int64      `json:"version"`
 }
@@ -0,0 +1,10 @@
+package responses
+
+type CacheKeyTemplateResponse struct {
+	Name    string `json:"name"`
+	Pattern string `json:"pattern"`
+}
+
+type CachePurgeResponse struct {
+	Deleted int `json:"deleted"`
+}
@@ -0,0 +1,6 @@
+package responses
+
+type HealthResponse struct {
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
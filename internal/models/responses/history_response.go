@@ -0,0 +1,24 @@
+package responses
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type FieldChangeResponse struct {
+	Field string          `json:"field"`
+	Old   json.RawMessage `json:"old,omitempty"`
+	New   json.RawMessage `json:"new,omitempty"`
+}
+
+type EntityHistoryResponse struct {
+	Id         uuid.UUID             `json:"id"`
+	EntityType string                `json:"entityType"`
+	EntityId   string                `json:"entityId"`
+	Action     string                `json:"action"`
+	Changes    []FieldChangeResponse `json:"changes"`
+	ActorId    *uuid.UUID            `json:"actorId,omitempty"`
+	OccurredAt time.Time             `json:"occurredAt"`
+}
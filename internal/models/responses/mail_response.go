@@ -0,0 +1,20 @@
+package responses
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type MailOutboxResponse struct {
+	Id             uuid.UUID  `json:"id"`
+	IdempotencyKey string     `json:"idempotencyKey"`
+	Recipient      string     `json:"recipient"`
+	Subject        string     `json:"subject"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"nextAttemptAt"`
+	LastError      string     `json:"lastError,omitempty"`
+	SentAt         *time.Time `json:"sentAt,omitempty"`
+	CreatedAt      *time.Time `json:"createdAt,omitempty"`
+}
@@ -0,0 +1,23 @@
+package responses
+
+import "github.com/google/uuid"
+
+type OrganizationResponse struct {
+	Id      uuid.UUID `json:"id"`
+	Name    string    `json:"name"`
+	OwnerId uuid.UUID `json:"ownerId"`
+	Role    string    `json:"role"`
+}
+
+type MemberResponse struct {
+	UserId   uuid.UUID `json:"userId"`
+	Email    string    `json:"email"`
+	FullName string    `json:"fullName"`
+	Role     string    `json:"role"`
+}
+
+type TeamResponse struct {
+	Id             uuid.UUID `json:"id"`
+	OrganizationId uuid.UUID `json:"organizationId"`
+	Name           string    `json:"name"`
+}
@@ -0,0 +1,10 @@
+package responses
+
+import "github.com/google/uuid"
+
+type TenantResponse struct {
+	Id        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Slug      string    `json:"slug"`
+	IsDefault bool      `json:"isDefault"`
+}
@@ -4,12 +4,19 @@ import (
 	configs "backend/pkg/config"
 	"backend/pkg/constants"
 	app_middlewares "backend/pkg/middlewares"
+	"fmt"
+	"net"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 )
 
-func ConfigMiddlewares(e *echo.Echo, appConfig *configs.AppConfig) {
+func ConfigMiddlewares(e *echo.Echo, appConfig *configs.AppConfig, tenantResolver app_middlewares.TenantResolver) error {
+	extractor, err := ipExtractor(appConfig.Server.TrustedProxies)
+	if err != nil {
+		return err
+	}
+	e.IPExtractor = extractor
 
 	if appConfig.Cors.Enable {
 		e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
@@ -21,6 +28,7 @@ func ConfigMiddlewares(e *echo.Echo, appConfig *configs.AppConfig) {
 				echo.HeaderXRequestID,
 				echo.HeaderXCSRFToken,
 				echo.HeaderAuthorization,
+				tenantHeader(appConfig),
 			},
 			AllowCredentials: true,
 		}))
@@ -31,5 +39,30 @@ func ConfigMiddlewares(e *echo.Echo, appConfig *configs.AppConfig) {
 	e.Use(app_middlewares.CorrelationIdMiddleware)
 	e.Use(middleware.RequestID())
 	e.Use(middleware.BodyLimit(constants.BodyLimit))
+	e.Use(app_middlewares.TenantMiddleware(appConfig, tenantResolver))
+	return nil
+}
 
+// ipExtractor only reads X-Forwarded-For when the request comes through a trusted proxy,
+// otherwise a client could pick its own IP and get around the per IP rate limits
+func ipExtractor(trustedProxies []string) (echo.IPExtractor, error) {
+	if len(trustedProxies) == 0 {
+		return echo.ExtractIPDirect(), nil
+	}
+	options := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, proxy := range trustedProxies {
+		_, ipRange, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		options = append(options, echo.TrustIPRange(ipRange))
+	}
+	return echo.ExtractIPFromXFFHeader(options...), nil
+}
+
+func tenantHeader(appConfig *configs.AppConfig) string {
+	if appConfig.Tenant.Header != "" {
+		return appConfig.Tenant.Header
+	}
+	return app_middlewares.HeaderTenantID
 }
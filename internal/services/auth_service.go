@@ -2,8 +2,10 @@ package services
 
 import (
 	"context"
+	"crypto/subtle"
 	"errors"
 	"fmt"
+	"time"
 
 	"backend/email_template"
 	"backend/internal/infrastructures/entities"
@@ -13,6 +15,7 @@ import (
 	"backend/internal/models/responses"
 	"backend/pkg/cache"
 	configs "backend/pkg/config"
+	"backend/pkg/database"
 	"backend/pkg/entity"
 	app_errors "backend/pkg/errors"
 	"backend/pkg/jwt_generate"
@@ -21,79 +24,142 @@ import (
 	"backend/pkg/response"
 	"backend/pkg/utils"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type IdentityService struct {
-	identityRepo repositories.UserRepository
-	redisCache   cache.Cache `name:"redis_identity"`
-	logger       logger.Logger
-	mailer       mailer.Mailer
-	appSetting   *configs.AppConfig
-	jwtGen       jwt_generate.JwtGenerate
+	identityRepo  repositories.UserRepository
+	roleRepo      repositories.RoleRepository
+	userRoleRepo  repositories.UserRoleRepository
+	tenantService *TenantService
+	unitOfWork    database.UnitOfWork
+	redisCache    cache.Cache `name:"redis_identity"`
+	userCache     *UserCache
+	logger        logger.Logger
+	mailer        mailer.Mailer
+	templates     *email_template.Renderer
+	appSetting    *configs.AppConfig
+	jwtGen        jwt_generate.JwtGenerate
 }
 
 var (
-	max_time_verify_otp = 3600
+	max_time_verify_otp = time.Hour
 )
 
 func NewIdentityService(identityRepo repositories.UserRepository,
+	roleRepo repositories.RoleRepository,
+	userRoleRepo repositories.UserRoleRepository,
+	tenantService *TenantService,
+	unitOfWork database.UnitOfWork,
 	redisCache cache.Cache,
+	userCache *UserCache,
 	logger logger.Logger,
 	mailer mailer.Mailer,
+	templates *email_template.Renderer,
 	appSetting *configs.AppConfig,
 	jwtGen jwt_generate.JwtGenerate,
 ) *IdentityService {
 
-	return &IdentityService{identityRepo: identityRepo, redisCache: redisCache, logger: logger, mailer: mailer, appSetting: appSetting, jwtGen: jwtGen}
+	return &IdentityService{identityRepo: identityRepo, roleRepo: roleRepo, userRoleRepo: userRoleRepo, tenantService: tenantService, unitOfWork: unitOfWork, redisCache: redisCache, userCache: userCache, logger: logger, mailer: mailer, templates: templates, appSetting: appSetting, jwtGen: jwtGen}
 }
 
+// Register queues the confirmation mail in the transaction creating the account, so neither commits without the other
 func (s *IdentityService) Register(ctx context.Context, request requests.CreateUserRequest) (bool, error) {
+	err := s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		user, err := s.createAccount(ctx, request, false)
+		if err != nil {
+			return err
+		}
+		token, _ := s.jwtGen.GenerateVerifyEmailToken(&jwt_generate.TokenPayload{
+			UserId: user.Id,
+			Email:  user.Email,
+		})
+		confirmUrl := fmt.Sprintf("%s/account/verify-account?token=%s", s.appSetting.ServiceUrl.Frontend, token)
+		mail, err := s.templates.Render(email_template.CONFIRM_ACCOUNT, user.Locale, &email_template.ConfirmAccountData{
+			ConfirmationURL: confirmUrl,
+		})
+
+		if err != nil {
+			s.logger.WithContext(ctx).Error("Cant not load Email Template")
+			return nil
+		}
+
+		mailCtx := mailer.WithIdempotencyKey(ctx, fmt.Sprintf("confirm_account:%s", user.Id))
+		err = s.mailer.Send(mailCtx, mail.Message(user.Email))
+		if errors.Is(err, mailer.ErrRecipientSuppressed) {
+			s.logger.WithContext(ctx).Warn("Confirmation email not sent, the address is suppressed")
+			return nil
+		}
+		if err != nil {
+			s.logger.WithContext(ctx).Error("Cant not send email")
+		}
+		return err
+	})
+	if err != nil {
+		var appErr app_errors.AppError
+		if errors.As(err, &appErr) {
+			return false, appErr
+		}
+		return false, app_errors.NewGeneralError(app_errors.DatabaseError)
+	}
+	return true, nil
+}
+
+// createAccount creates a user with the default role, emailConfirmed is set when the email was already proven, as by an invitation
+func (s *IdentityService) createAccount(ctx context.Context, request requests.CreateUserRequest, emailConfirmed bool) (*entities.User, error) {
 	user, err := s.identityRepo.FindByEmail(ctx, request.Email)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		return false, app_errors.NewGeneralError(app_errors.DatabaseError)
+		return nil, app_errors.NewGeneralError(app_errors.DatabaseError)
 	}
 
 	if user != nil {
-		return false, identity_errors.NewIdentityError(identity_errors.EmailExisted)
+		return nil, identity_errors.NewIdentityError(identity_errors.EmailExisted)
 	}
 
 	passwordHash, err := utils.HashPassword(request.Password)
 
 	if err != nil {
-		return false, identity_errors.NewIdentityError(identity_errors.CanNotHashPassword)
+		return nil, identity_errors.NewIdentityError(identity_errors.CanNotHashPassword)
 	}
 
 	newUser := &entities.User{
-		BaseAuditTrackingEntity: entity.NewSQLModel(),
-		Email:                   request.Email,
-		PasswordHash:            passwordHash,
-		EmailConfirm:            false,
-		FirstName:               request.FirstName,
-		LastName:                request.LastName,
+		BaseEntitySoftDelete: entity.NewSoftDeleteSQLModel(),
+		Email:                request.Email,
+		PasswordHash:         passwordHash,
+		EmailConfirm:         emailConfirmed,
+		FirstName:            request.FirstName,
+		LastName:             request.LastName,
+		Locale:               email_template.NormalizeLocale(request.Locale),
 	}
-	user, err = s.identityRepo.Create(newUser, ctx)
-
-	if err != nil {
-		return false, app_errors.NewGeneralError(app_errors.DatabaseError)
-	}
-	token, _ := s.jwtGen.GenerateVerifyEmailToken(&jwt_generate.TokenPayload{
-		UserId: user.Id,
-		Email:  user.Email,
-	})
-	confirmUrl := fmt.Sprintf("%s/account/verify-account?token=%s", s.appSetting.ServiceUrl.Frontend, token)
-	template, err := email_template.LoadTemplate(email_template.CONFIRM_ACCOUNT, &email_template.ConfirmAccountData{
-		ConfirmationURL: confirmUrl,
+	err = s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if user, err = s.identityRepo.Create(newUser, ctx); err != nil {
+			return err
+		}
+		return s.assignDefaultRole(ctx, user)
 	})
 
 	if err != nil {
-		s.logger.WithContext(ctx).Error("Cant not load Email Template")
+		return nil, app_errors.NewGeneralError(app_errors.DatabaseError)
 	}
+	return user, nil
+}
 
-	if err := s.mailer.SendHTML(ctx, user.Email, "Welcome to AppName - Verify Your Account", template); err != nil {
-		s.logger.WithContext(ctx).Error("Cant not send email")
+func (s *IdentityService) assignDefaultRole(ctx context.Context, user *entities.User) error {
+	role, err := s.roleRepo.FindByCode(ctx, entities.Role_User)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		s.logger.WithContext(ctx).Warnf("Default role %s is not seeded", entities.Role_User)
+		return nil
 	}
-	return true, nil
+	if err != nil {
+		return err
+	}
+	_, err = s.userRoleRepo.Create(&entities.UserRole{
+		BaseAuditTrackingEntity: entity.NewSQLModel(),
+		UserId:                  uuid.NullUUID{UUID: user.Id, Valid: true},
+		RoleId:                  uuid.NullUUID{UUID: role.Id, Valid: true},
+	}, ctx)
+	return err
 }
 
 func (s *IdentityService) Login(ctx context.Context, request requests.LoginRequest) *response.Response[*responses.AuthenResponse] {
@@ -115,9 +181,15 @@ func (s *IdentityService) Login(ctx context.Context, request requests.LoginReque
 		return response.FailureWithData[*responses.AuthenResponse](nil, identity_errors.NewIdentityError(identity_errors.EmailNotConfirmed))
 	}
 
+	tenantId, err := s.tenantService.DefaultTenant(ctx, user.Id)
+	if err != nil {
+		return response.FailureWithData[*responses.AuthenResponse](nil, app_errors.NewGeneralError(app_errors.DatabaseError))
+	}
+
 	token, err := s.jwtGen.GenerateToken(&jwt_generate.TokenPayload{
-		UserId: user.Id,
-		Email:  user.Email,
+		UserId:   user.Id,
+		Email:    user.Email,
+		TenantId: tenantId,
 	})
 	if err != nil {
 		return response.FailureWithData[*responses.AuthenResponse](nil, identity_errors.NewIdentityError(identity_errors.JWTError))
@@ -140,6 +212,7 @@ func (s *IdentityService) Login(ctx context.Context, request requests.LoginReque
 }
 
 func (s *IdentityService) VerifyEmail(ctx context.Context, token string) *response.Response[bool] {
+	ctx = database.WithPrimary(ctx)
 	payload, err := s.jwtGen.VerifyToken(token, s.appSetting.Jwt.VerifyEmailSecretKey)
 
 	if err != nil {
@@ -161,11 +234,13 @@ func (s *IdentityService) VerifyEmail(ctx context.Context, token string) *respon
 	if err = s.identityRepo.Update(user, ctx); err != nil {
 		return response.Failure(app_errors.NewGeneralError(app_errors.DatabaseError))
 	}
+	s.invalidateUser(ctx, user)
 
 	return response.Success(true)
 }
 
 func (s *IdentityService) ResetPassword(ctx context.Context, request requests.ResetPasswordRequest) *response.Response[bool] {
+	ctx = database.WithPrimary(ctx)
 	user, err := s.identityRepo.FindByEmail(ctx, request.Email)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		return response.Failure(app_errors.NewGeneralError(app_errors.DatabaseError))
@@ -174,11 +249,13 @@ func (s *IdentityService) ResetPassword(ctx context.Context, request requests.Re
 	if user == nil {
 		return response.Failure(identity_errors.NewIdentityError(identity_errors.EmailNotFound))
 	}
-	otp, err := s.redisCache.Get(ctx, cache.ForgotPasswordKey(user.Id))
+	otpKey := cache.ForgotPasswordKey.Build(user.Id)
+	otp, err := s.redisCache.Get(ctx, otpKey)
 	if err != nil {
 		return response.Failure(app_errors.NewGeneralError(app_errors.DatabaseError))
 	}
-	if otp != request.Code {
+	// a missing otp reads as "", which must never match an empty code
+	if otp == "" || request.Code == "" || subtle.ConstantTimeCompare([]byte(otp), []byte(request.Code)) != 1 {
 		return response.Failure(identity_errors.NewIdentityError(identity_errors.OTPInvalid))
 	}
 
@@ -191,6 +268,11 @@ func (s *IdentityService) ResetPassword(ctx context.Context, request requests.Re
 	if err = s.identityRepo.Update(user, ctx); err != nil {
 		return response.Failure(app_errors.NewGeneralError(app_errors.DatabaseError))
 	}
+	// the otp is single use
+	if err := s.redisCache.Delete(ctx, otpKey); err != nil {
+		s.logger.WithContext(ctx).Error("Cant not delete otp from redis")
+	}
+	s.invalidateUser(ctx, user)
 	return response.Success(true)
 }
 
@@ -206,21 +288,32 @@ func (s *IdentityService) ForgotPassword(ctx context.Context, request requests.F
 
 	otp := utils.GenerateSecureOTP()
 
-	if err := s.redisCache.Set(ctx, cache.ForgotPasswordKey(user.Id), otp, max_time_verify_otp); err != nil {
+	otpKey := cache.ForgotPasswordKey.Build(user.Id)
+	if err := s.redisCache.Set(ctx, otpKey, otp, max_time_verify_otp); err != nil {
 		s.logger.WithContext(ctx).Error("Cant not set otp to redis")
+	} else if err := cache.Tag(ctx, s.redisCache, otpKey, max_time_verify_otp, cache.UserTag(user.Id)); err != nil {
+		s.logger.WithContext(ctx).Error("Cant not tag otp in redis")
 	}
 
-	template, err := email_template.LoadTemplate(email_template.FORGOT_PASSWORD, &email_template.ForgotPasswordData{
+	mail, err := s.templates.Render(email_template.FORGOT_PASSWORD, user.Locale, &email_template.ForgotPasswordData{
 		Token: otp,
 	})
 
 	if err != nil {
 		s.logger.WithContext(ctx).Error("Cant not load Email Template")
+		return response.Success(true)
 	}
 
-	if err := s.mailer.SendHTML(ctx, user.Email, "AppName - Reset Password", template); err != nil {
+	if err := s.mailer.Send(ctx, mail.Message(user.Email)); err != nil {
 		s.logger.WithContext(ctx).Error("Cant not send email")
 	}
 
 	return response.Success(true)
 }
+
+// invalidateUser drops the cached profile of a user that was just saved
+func (s *IdentityService) invalidateUser(ctx context.Context, user *entities.User) {
+	if err := s.userCache.Delete(ctx, cache.UserKey.Build(user.Id)); err != nil {
+		s.logger.WithContext(ctx).Error("Cant not invalidate user cache")
+	}
+}
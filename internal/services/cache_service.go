@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"backend/internal/models/responses"
+	"backend/pkg/cache"
+	app_errors "backend/pkg/errors"
+	"backend/pkg/logger"
+	"backend/pkg/response"
+
+	"github.com/google/uuid"
+)
+
+// CacheService backs the admin tooling to inspect and drop cached keys
+type CacheService struct {
+	invalidator cache.Invalidator
+	logger      logger.Logger
+}
+
+func NewCacheService(invalidator cache.Invalidator, logger logger.Logger) *CacheService {
+	return &CacheService{invalidator: invalidator, logger: logger}
+}
+
+// KeyTemplates lists the known kinds of keys with the pattern to purge them
+func (s *CacheService) KeyTemplates() *response.Response[[]responses.CacheKeyTemplateResponse] {
+	templates := cache.KeyTemplates()
+	result := make([]responses.CacheKeyTemplateResponse, 0, len(templates))
+	for name, pattern := range templates {
+		result = append(result, responses.CacheKeyTemplateResponse{Name: name, Pattern: pattern})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return response.Success(result)
+}
+
+// Purge deletes the keys of this service matching pattern
+func (s *CacheService) Purge(ctx context.Context, pattern string) *response.Response[*responses.CachePurgeResponse] {
+	if strings.TrimSpace(pattern) == "" {
+		return response.FailureWithData[*responses.CachePurgeResponse](nil, app_errors.NewGeneralError(app_errors.DataInvalid))
+	}
+	deleted, err := s.invalidator.Purge(ctx, pattern)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Cant not purge cache keys")
+		return response.FailureWithData[*responses.CachePurgeResponse](nil, app_errors.NewGeneralError(app_errors.DatabaseError))
+	}
+	return response.Success(&responses.CachePurgeResponse{Deleted: deleted})
+}
+
+func (s *CacheService) InvalidateTag(ctx context.Context, tag string) *response.Response[*responses.CachePurgeResponse] {
+	if strings.TrimSpace(tag) == "" {
+		return response.FailureWithData[*responses.CachePurgeResponse](nil, app_errors.NewGeneralError(app_errors.DataInvalid))
+	}
+	deleted, err := s.invalidator.InvalidateTag(ctx, tag)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Cant not invalidate cache tag")
+		return response.FailureWithData[*responses.CachePurgeResponse](nil, app_errors.NewGeneralError(app_errors.DatabaseError))
+	}
+	return response.Success(&responses.CachePurgeResponse{Deleted: deleted})
+}
+
+// InvalidateUser drops every cached key of a user: profile, refresh token and pending otp codes
+func (s *CacheService) InvalidateUser(ctx context.Context, userId uuid.UUID) *response.Response[*responses.CachePurgeResponse] {
+	return s.InvalidateTag(ctx, cache.UserTag(userId))
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"context"
+
+	"backend/internal/infrastructures/repositories"
+	"backend/internal/models/responses"
+	"backend/pkg/database"
+	app_errors "backend/pkg/errors"
+	"backend/pkg/logger"
+	"backend/pkg/response"
+)
+
+type HistoryService struct {
+	historyRepo repositories.EntityHistoryRepository
+	logger      logger.Logger
+}
+
+func NewHistoryService(historyRepo repositories.EntityHistoryRepository, logger logger.Logger) *HistoryService {
+	return &HistoryService{historyRepo: historyRepo, logger: logger}
+}
+
+// GetHistory lists the recorded changes of an entity, newest first
+func (s *HistoryService) GetHistory(ctx context.Context, entityType string, entityId string) *response.Response[[]*responses.EntityHistoryResponse] {
+	history, err := s.historyRepo.FindByEntity(ctx, entityType, entityId)
+	if err != nil {
+		return response.FailureWithData[[]*responses.EntityHistoryResponse](nil, app_errors.NewGeneralError(app_errors.DatabaseError))
+	}
+	result := make([]*responses.EntityHistoryResponse, 0, len(history))
+	for i := range history {
+		result = append(result, toEntityHistoryResponse(&history[i]))
+	}
+	return response.Success(result)
+}
+
+func toEntityHistoryResponse(history *database.EntityHistory) *responses.EntityHistoryResponse {
+	changes := make([]responses.FieldChangeResponse, 0, len(history.Changes))
+	for _, change := range history.Changes {
+		changes = append(changes, responses.FieldChangeResponse{Field: change.Field, Old: change.Old, New: change.New})
+	}
+	result := &responses.EntityHistoryResponse{
+		Id:         history.Id,
+		EntityType: history.EntityType,
+		EntityId:   history.EntityId,
+		Action:     history.Action,
+		Changes:    changes,
+		OccurredAt: history.OccurredAt,
+	}
+	if history.ActorId.Valid {
+		result.ActorId = &history.ActorId.UUID
+	}
+	return result
+}
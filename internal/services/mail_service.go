@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"backend/internal/infrastructures/repositories"
+	"backend/internal/models/responses"
+	configs "backend/pkg/config"
+	"backend/pkg/database"
+	"backend/pkg/entity"
+	app_errors "backend/pkg/errors"
+	"backend/pkg/logger"
+	"backend/pkg/mailer"
+	"backend/pkg/response"
+	"backend/pkg/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MailService backs the admin tooling of the mail outbox and the bounce webhook
+type MailService struct {
+	outbox       repositories.MailOutboxRepository
+	suppressions mailer.SuppressionStore
+	worker       *mailer.OutboxWorker
+	codec        *utils.CursorCodec
+	appConfig    *configs.AppConfig
+	logger       logger.Logger
+}
+
+func NewMailService(outbox repositories.MailOutboxRepository, suppressions mailer.SuppressionStore, worker *mailer.OutboxWorker,
+	codec *utils.CursorCodec, appConfig *configs.AppConfig, logger logger.Logger) *MailService {
+	return &MailService{outbox: outbox, suppressions: suppressions, worker: worker, codec: codec, appConfig: appConfig, logger: logger}
+}
+
+// ListOutbox lists a page of outbox messages, newest first, status (PENDING, SENDING, SENT, DEAD) narrows it down
+func (s *MailService) ListOutbox(ctx context.Context, status string, page *utils.CursorPagination) *response.ResponseWithCursor[[]*responses.MailOutboxResponse] {
+	messages, cursors, err := s.outbox.Page(ctx, status, page)
+	if errors.Is(err, database.ErrUnsortable) {
+		return response.FailureWithCursor[[]*responses.MailOutboxResponse](nil, app_errors.NewGeneralError(app_errors.DataInvalid))
+	}
+	if err != nil {
+		s.logger.WithContext(ctx).Errorf("Cant not read the mail outbox: %v", err)
+		return response.FailureWithCursor[[]*responses.MailOutboxResponse](nil, app_errors.NewGeneralError(app_errors.DatabaseError))
+	}
+	next, prev, err := s.codec.EncodePage(cursors)
+	if err != nil {
+		return response.FailureWithCursor[[]*responses.MailOutboxResponse](nil, app_errors.NewGeneralError(app_errors.DataInvalid))
+	}
+	result := make([]*responses.MailOutboxResponse, 0, len(messages))
+	for i := range messages {
+		result = append(result, toMailOutboxResponse(&messages[i]))
+	}
+	return response.SuccessWithCursor(result, next, prev)
+}
+
+func (s *MailService) GetOutbox(ctx context.Context, id uuid.UUID) *response.Response[*responses.MailOutboxResponse] {
+	message, err := s.outbox.Find(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return response.FailureWithData[*responses.MailOutboxResponse](nil, app_errors.NewGeneralError(app_errors.NotFound))
+	}
+	if err != nil {
+		return response.FailureWithData[*responses.MailOutboxResponse](nil, app_errors.NewGeneralError(app_errors.DatabaseError))
+	}
+	return response.Success(toMailOutboxResponse(message))
+}
+
+// Resend queues a sent or dead-lettered message again, a message still queued is left as is
+func (s *MailService) Resend(ctx context.Context, id uuid.UUID) *response.Response[*responses.MailOutboxResponse] {
+	// read from the primary, a lagging replica would report a status the worker already moved on from
+	ctx = database.WithPrimary(ctx)
+	message, err := s.outbox.Find(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return response.FailureWithData[*responses.MailOutboxResponse](nil, app_errors.NewGeneralError(app_errors.NotFound))
+	}
+	if err != nil {
+		return response.FailureWithData[*responses.MailOutboxResponse](nil, app_errors.NewGeneralError(app_errors.DatabaseError))
+	}
+	if message.Status != mailer.OutboxSent && message.Status != mailer.OutboxDead {
+		return response.FailureWithData[*responses.MailOutboxResponse](nil, app_errors.NewGeneralError(app_errors.DataConflict))
+	}
+
+	message.Resend(time.Now().UTC())
+	if err := s.outbox.Save(ctx, message); err != nil {
+		s.logger.WithContext(ctx).Error("Cant not resend outbox message")
+		return response.FailureWithData[*responses.MailOutboxResponse](nil, app_errors.NewGeneralError(app_errors.DatabaseError))
+	}
+	s.worker.Wake()
+	return response.Success(toMailOutboxResponse(message))
+}
+
+// HandleBounceWebhook suppresses the addresses provider reports as hard bounced or complained about, it returns how many
+func (s *MailService) HandleBounceWebhook(ctx context.Context, provider string, body []byte) *response.Response[int] {
+	notification, err := mailer.ParseBounceNotification(provider, body, s.appConfig.Mail.Webhook, time.Now())
+	if err != nil {
+		s.logger.WithContext(ctx).Warnf("Cant not read %s bounce webhook: %v", provider, err)
+		return response.FailureWithData(0, app_errors.NewGeneralError(app_errors.DataInvalid))
+	}
+	if notification.SubscribeURL != "" {
+		s.logger.WithContext(ctx).Infof("Confirm the SNS subscription of the bounce webhook at %s", notification.SubscribeURL)
+	}
+
+	for _, event := range notification.Events {
+		err := s.suppressions.Suppress(ctx, &mailer.Suppression{
+			BaseAuditTrackingEntity: entity.NewSQLModel(),
+			Address:                 event.Address,
+			Reason:                  event.Reason,
+			Provider:                strings.ToLower(provider),
+			Detail:                  event.Detail,
+		})
+		if err != nil {
+			s.logger.WithContext(ctx).Error("Cant not suppress bounced address")
+			return response.FailureWithData(0, app_errors.NewGeneralError(app_errors.DatabaseError))
+		}
+	}
+	return response.Success(len(notification.Events))
+}
+
+func toMailOutboxResponse(message *mailer.OutboxMessage) *responses.MailOutboxResponse {
+	return &responses.MailOutboxResponse{
+		Id:             message.Id,
+		IdempotencyKey: message.IdempotencyKey,
+		Recipient:      message.Recipient,
+		Subject:        message.Subject,
+		Status:         message.Status,
+		Attempts:       message.Attempts,
+		NextAttemptAt:  message.NextAttemptAt,
+		LastError:      message.LastError,
+		SentAt:         message.SentAt,
+		CreatedAt:      message.CreatedDateTimeUtc,
+	}
+}
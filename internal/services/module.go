@@ -8,5 +8,14 @@ var Module = fx.Module("services",
 	fx.Provide(
 		NewIdentityService,
 		NewUserService,
+		NewUserCache,
+		NewTenantService,
+		NewTenantResolver,
+		NewOrganizationService,
+		NewRoleService,
+		NewRoleChecker,
+		NewHistoryService,
+		NewCacheService,
+		NewMailService,
 	),
 )
@@ -0,0 +1,564 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"backend/email_template"
+	"backend/internal/infrastructures/entities"
+	identity_errors "backend/internal/infrastructures/errors"
+	"backend/internal/infrastructures/repositories"
+	"backend/internal/models/requests"
+	"backend/internal/models/responses"
+	configs "backend/pkg/config"
+	"backend/pkg/database"
+	"backend/pkg/entity"
+	app_errors "backend/pkg/errors"
+	"backend/pkg/jwt_generate"
+	"backend/pkg/logger"
+	"backend/pkg/mailer"
+	"backend/pkg/response"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type OrganizationService struct {
+	organizationRepo repositories.OrganizationRepository
+	teamRepo         repositories.TeamRepository
+	invitationRepo   repositories.InvitationRepository
+	userRepo         repositories.UserRepository
+	roleRepo         repositories.RoleRepository
+	userRoleRepo     repositories.UserRoleRepository
+	identityService  *IdentityService
+	unitOfWork       database.UnitOfWork
+	logger           logger.Logger
+	mailer           mailer.Mailer
+	templates        *email_template.Renderer
+	appSetting       *configs.AppConfig
+	jwtGen           jwt_generate.JwtGenerate
+}
+
+// membership is the organization role a user holds along with the grant carrying it
+type membership struct {
+	role     *entities.Role
+	userRole *entities.UserRole
+}
+
+func (m *membership) canManage() bool {
+	return m.role.Code == entities.Role_OrgOwner || m.role.Code == entities.Role_OrgAdmin
+}
+
+func NewOrganizationService(organizationRepo repositories.OrganizationRepository,
+	teamRepo repositories.TeamRepository,
+	invitationRepo repositories.InvitationRepository,
+	userRepo repositories.UserRepository,
+	roleRepo repositories.RoleRepository,
+	userRoleRepo repositories.UserRoleRepository,
+	identityService *IdentityService,
+	unitOfWork database.UnitOfWork,
+	logger logger.Logger,
+	mailer mailer.Mailer,
+	templates *email_template.Renderer,
+	appSetting *configs.AppConfig,
+	jwtGen jwt_generate.JwtGenerate,
+) *OrganizationService {
+	return &OrganizationService{organizationRepo: organizationRepo, teamRepo: teamRepo, invitationRepo: invitationRepo, userRepo: userRepo,
+		roleRepo: roleRepo, userRoleRepo: userRoleRepo, identityService: identityService, unitOfWork: unitOfWork, logger: logger,
+		mailer: mailer, templates: templates, appSetting: appSetting, jwtGen: jwtGen}
+}
+
+// CreateOrganization creates an organization owned by its creator
+func (s *OrganizationService) CreateOrganization(ctx context.Context, userId uuid.UUID, request requests.CreateOrganizationRequest) *response.Response[*responses.OrganizationResponse] {
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		return response.FailureWithData[*responses.OrganizationResponse](nil, app_errors.NewGeneralError(app_errors.DataInvalid))
+	}
+
+	organization := &entities.Organization{
+		BaseAuditTrackingEntity: entity.NewSQLModel(),
+		Name:                    name,
+		OwnerId:                 userId,
+	}
+	err := s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if _, err := s.organizationRepo.Create(organization, ctx); err != nil {
+			return err
+		}
+		return s.grantRole(ctx, userId, entities.Role_OrgOwner, organization.Id)
+	})
+	if err != nil {
+		return response.FailureWithData[*responses.OrganizationResponse](nil, app_errors.NewGeneralError(app_errors.DatabaseError))
+	}
+	return response.Success(toOrganizationResponse(organization, entities.Role_OrgOwner))
+}
+
+// ListOrganizations lists the organizations the user is a member of
+func (s *OrganizationService) ListOrganizations(ctx context.Context, userId uuid.UUID) *response.Response[[]*responses.OrganizationResponse] {
+	organizations, err := s.organizationRepo.FindByMember(ctx, userId)
+	if err != nil {
+		return response.FailureWithData[[]*responses.OrganizationResponse](nil, app_errors.NewGeneralError(app_errors.DatabaseError))
+	}
+
+	result := make([]*responses.OrganizationResponse, 0, len(organizations))
+	for i := range organizations {
+		member, err := s.membershipOf(ctx, organizations[i].Id, userId)
+		if err != nil {
+			return response.FailureWithData[[]*responses.OrganizationResponse](nil, app_errors.NewGeneralError(app_errors.DatabaseError))
+		}
+		if member != nil {
+			result = append(result, toOrganizationResponse(&organizations[i], member.role.Code))
+		}
+	}
+	return response.Success(result)
+}
+
+// ListMembers lists the members of an organization the user belongs to
+func (s *OrganizationService) ListMembers(ctx context.Context, userId uuid.UUID, organizationId uuid.UUID) *response.Response[[]*responses.MemberResponse] {
+	if _, _, appErr := s.authorize(ctx, organizationId, userId, false); appErr != nil {
+		return response.FailureWithData[[]*responses.MemberResponse](nil, appErr)
+	}
+
+	userRoles, err := s.userRoleRepo.FindByScope(ctx, organizationId)
+	if err != nil {
+		return response.FailureWithData[[]*responses.MemberResponse](nil, app_errors.NewGeneralError(app_errors.DatabaseError))
+	}
+	result := make([]*responses.MemberResponse, 0, len(userRoles))
+	for _, userRole := range userRoles {
+		role, err := s.roleRepo.GetByID(userRole.RoleId.UUID, ctx)
+		if err != nil {
+			return response.FailureWithData[[]*responses.MemberResponse](nil, app_errors.NewGeneralError(app_errors.DatabaseError))
+		}
+		user, err := s.userRepo.GetByID(userRole.UserId.UUID, ctx)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return response.FailureWithData[[]*responses.MemberResponse](nil, app_errors.NewGeneralError(app_errors.DatabaseError))
+		}
+		result = append(result, &responses.MemberResponse{
+			UserId:   user.Id,
+			Email:    user.Email,
+			FullName: user.FullName(),
+			Role:     role.Code,
+		})
+	}
+	return response.Success(result)
+}
+
+// Invite emails a tokenized invitation link, pending invitations of the same email are revoked
+func (s *OrganizationService) Invite(ctx context.Context, userId uuid.UUID, organizationId uuid.UUID, request requests.InviteMemberRequest) *response.Response[bool] {
+	email := strings.ToLower(strings.TrimSpace(request.Email))
+	roleCode := request.Role
+	if roleCode == "" {
+		roleCode = entities.Role_OrgMember
+	}
+	if email == "" || (roleCode != entities.Role_OrgMember && roleCode != entities.Role_OrgAdmin) {
+		return response.Failure(app_errors.NewGeneralError(app_errors.DataInvalid))
+	}
+
+	organization, _, appErr := s.authorize(ctx, organizationId, userId, true)
+	if appErr != nil {
+		return response.Failure(appErr)
+	}
+
+	invitee, err := s.userRepo.FindByEmail(ctx, email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return response.Failure(app_errors.NewGeneralError(app_errors.DatabaseError))
+	}
+	if invitee != nil {
+		member, err := s.membershipOf(ctx, organizationId, invitee.Id)
+		if err != nil {
+			return response.Failure(app_errors.NewGeneralError(app_errors.DatabaseError))
+		}
+		if member != nil {
+			return response.Failure(identity_errors.NewIdentityError(identity_errors.MemberExisted))
+		}
+	}
+
+	role, err := s.roleRepo.FindByCode(ctx, roleCode)
+	if err != nil {
+		return response.Failure(app_errors.NewGeneralError(app_errors.DatabaseError))
+	}
+	invitation := &entities.Invitation{
+		BaseAuditTrackingEntity: entity.NewSQLModel(),
+		OrganizationId:          organizationId,
+		Email:                   email,
+		RoleId:                  role.Id,
+		Status:                  entities.Invitation_Pending,
+		ExpiresAt:               time.Now().UTC().Add(time.Duration(s.appSetting.Jwt.InviteTokenExpire) * time.Hour),
+	}
+	token, err := s.jwtGen.GenerateInviteToken(&jwt_generate.TokenPayload{
+		Email:        email,
+		InvitationId: invitation.Id,
+	})
+	if err != nil {
+		return response.Failure(identity_errors.NewIdentityError(identity_errors.JWTError))
+	}
+
+	// the mail speaks the language of the invitee when they have an account, of the inviter otherwise
+	inviterName, locale := "A teammate", ""
+	if inviter, err := s.userRepo.GetByID(userId, ctx); err == nil {
+		inviterName, locale = inviter.FullName(), inviter.Locale
+	}
+	if invitee != nil && invitee.Locale != "" {
+		locale = invitee.Locale
+	}
+	invitationUrl := fmt.Sprintf("%s/account/accept-invitation?token=%s", s.appSetting.ServiceUrl.Frontend, token)
+	// rendered before anything is stored, an invitation whose mail can not go out is not created
+	mail, err := s.templates.Render(email_template.INVITATION, locale, &email_template.InvitationData{
+		InviterName:      inviterName,
+		OrganizationName: organization.Name,
+		InvitationURL:    invitationUrl,
+		ExpireHours:      s.appSetting.Jwt.InviteTokenExpire,
+	})
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Cant not load Email Template")
+		return response.Failure(identity_errors.NewIdentityError(identity_errors.InvitationMailFailed))
+	}
+
+	err = s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		pending, err := s.invitationRepo.FindPending(ctx, organizationId, email)
+		if err != nil {
+			return err
+		}
+		for i := range pending {
+			pending[i].Status = entities.Invitation_Revoked
+			if err := s.invitationRepo.Update(&pending[i], ctx); err != nil {
+				return err
+			}
+		}
+		if _, err = s.invitationRepo.Create(invitation, ctx); err != nil {
+			return err
+		}
+		mailCtx := mailer.WithIdempotencyKey(ctx, fmt.Sprintf("invitation:%s", invitation.Id))
+		return s.mailer.Send(mailCtx, mail.Message(email))
+	})
+	if errors.Is(err, mailer.ErrRecipientSuppressed) {
+		s.logger.WithContext(ctx).Warn("Invitation not created, the address is suppressed")
+		return response.Failure(identity_errors.NewIdentityError(identity_errors.InvitationMailFailed))
+	}
+	if err != nil {
+		return response.Failure(app_errors.NewGeneralError(app_errors.DatabaseError))
+	}
+	return response.Success(true)
+}
+
+// AcceptInvitation joins the invited email to the organization, creating its account first when it has none
+func (s *OrganizationService) AcceptInvitation(ctx context.Context, request requests.AcceptInvitationRequest) *response.Response[bool] {
+	ctx = database.WithPrimary(ctx)
+	payload, err := s.jwtGen.VerifyToken(request.Token, s.appSetting.Jwt.InviteSecretKey)
+	if err != nil || payload.InvitationId == uuid.Nil {
+		return response.Failure(identity_errors.NewIdentityError(identity_errors.InvitationInvalid))
+	}
+
+	invitation, err := s.invitationRepo.GetByID(payload.InvitationId, ctx)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return response.Failure(identity_errors.NewIdentityError(identity_errors.InvitationInvalid))
+	}
+	if err != nil {
+		return response.Failure(app_errors.NewGeneralError(app_errors.DatabaseError))
+	}
+	if invitation.Status != entities.Invitation_Pending || !strings.EqualFold(invitation.Email, payload.Email) {
+		return response.Failure(identity_errors.NewIdentityError(identity_errors.InvitationInvalid))
+	}
+	if time.Now().UTC().After(invitation.ExpiresAt) {
+		return response.Failure(identity_errors.NewIdentityError(identity_errors.InvitationExpired))
+	}
+
+	user, err := s.userRepo.FindByEmail(ctx, invitation.Email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return response.Failure(app_errors.NewGeneralError(app_errors.DatabaseError))
+	}
+	if user == nil && request.Password == "" {
+		return response.Failure(app_errors.NewGeneralError(app_errors.DataInvalid))
+	}
+
+	err = s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if user == nil {
+			if user, err = s.identityService.createAccount(ctx, requests.CreateUserRequest{
+				Email:     invitation.Email,
+				Password:  request.Password,
+				FirstName: request.FirstName,
+				LastName:  request.LastName,
+				Locale:    request.Locale,
+			}, true); err != nil {
+				return err
+			}
+		}
+
+		member, err := s.membershipOf(ctx, invitation.OrganizationId, user.Id)
+		if err != nil {
+			return err
+		}
+		if member == nil {
+			if _, err := s.userRoleRepo.Create(&entities.UserRole{
+				BaseAuditTrackingEntity: entity.NewSQLModel(),
+				UserId:                  uuid.NullUUID{UUID: user.Id, Valid: true},
+				RoleId:                  uuid.NullUUID{UUID: invitation.RoleId, Valid: true},
+				ScopeId:                 uuid.NullUUID{UUID: invitation.OrganizationId, Valid: true},
+			}, ctx); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now().UTC()
+		invitation.Status = entities.Invitation_Accepted
+		invitation.AcceptedBy = uuid.NullUUID{UUID: user.Id, Valid: true}
+		invitation.AcceptedDateTimeUtc = &now
+		return s.invitationRepo.Update(invitation, ctx)
+	})
+	if err != nil {
+		var appErr app_errors.AppError
+		if errors.As(err, &appErr) {
+			return response.Failure(appErr)
+		}
+		return response.Failure(app_errors.NewGeneralError(app_errors.DatabaseError))
+	}
+	return response.Success(true)
+}
+
+// TransferOwnership hands the organization over to another member, the previous owner stays on as admin
+func (s *OrganizationService) TransferOwnership(ctx context.Context, userId uuid.UUID, organizationId uuid.UUID, request requests.TransferOwnershipRequest) *response.Response[bool] {
+	ctx = database.WithPrimary(ctx)
+	organization, current, appErr := s.authorize(ctx, organizationId, userId, true)
+	if appErr != nil {
+		return response.Failure(appErr)
+	}
+	if current.role.Code != entities.Role_OrgOwner {
+		return response.Failure(identity_errors.NewIdentityError(identity_errors.OrganizationPermissionDenied))
+	}
+	if request.UserId == userId {
+		return response.Success(true)
+	}
+
+	target, err := s.membershipOf(ctx, organizationId, request.UserId)
+	if err != nil {
+		return response.Failure(app_errors.NewGeneralError(app_errors.DatabaseError))
+	}
+	if target == nil {
+		return response.Failure(identity_errors.NewIdentityError(identity_errors.NotOrganizationMember))
+	}
+
+	ownerRole, err := s.roleRepo.FindByCode(ctx, entities.Role_OrgOwner)
+	if err != nil {
+		return response.Failure(app_errors.NewGeneralError(app_errors.DatabaseError))
+	}
+	adminRole, err := s.roleRepo.FindByCode(ctx, entities.Role_OrgAdmin)
+	if err != nil {
+		return response.Failure(app_errors.NewGeneralError(app_errors.DatabaseError))
+	}
+
+	err = s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		target.userRole.RoleId = uuid.NullUUID{UUID: ownerRole.Id, Valid: true}
+		if err := s.userRoleRepo.Update(target.userRole, ctx); err != nil {
+			return err
+		}
+		current.userRole.RoleId = uuid.NullUUID{UUID: adminRole.Id, Valid: true}
+		if err := s.userRoleRepo.Update(current.userRole, ctx); err != nil {
+			return err
+		}
+		organization.OwnerId = request.UserId
+		return s.organizationRepo.Update(organization, ctx)
+	})
+	if err != nil {
+		return response.Failure(app_errors.NewGeneralError(app_errors.DatabaseError))
+	}
+	return response.Success(true)
+}
+
+// RemoveMember removes a member along with their team roles, members may remove themselves and only the owner removes admins
+func (s *OrganizationService) RemoveMember(ctx context.Context, userId uuid.UUID, organizationId uuid.UUID, memberId uuid.UUID) *response.Response[bool] {
+	ctx = database.WithPrimary(ctx)
+	_, current, appErr := s.authorize(ctx, organizationId, userId, memberId != userId)
+	if appErr != nil {
+		return response.Failure(appErr)
+	}
+
+	target, err := s.membershipOf(ctx, organizationId, memberId)
+	if err != nil {
+		return response.Failure(app_errors.NewGeneralError(app_errors.DatabaseError))
+	}
+	if target == nil {
+		return response.Failure(identity_errors.NewIdentityError(identity_errors.NotOrganizationMember))
+	}
+	if target.role.Code == entities.Role_OrgOwner {
+		return response.Failure(identity_errors.NewIdentityError(identity_errors.CanNotRemoveOwner))
+	}
+	if target.role.Code == entities.Role_OrgAdmin && memberId != userId && current.role.Code != entities.Role_OrgOwner {
+		return response.Failure(identity_errors.NewIdentityError(identity_errors.OrganizationPermissionDenied))
+	}
+
+	teams, err := s.teamRepo.FindByOrganization(ctx, organizationId)
+	if err != nil {
+		return response.Failure(app_errors.NewGeneralError(app_errors.DatabaseError))
+	}
+	scopeIds := []uuid.UUID{organizationId}
+	for _, team := range teams {
+		scopeIds = append(scopeIds, team.Id)
+	}
+
+	err = s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		userRoles, err := s.userRoleRepo.FindUserScoped(ctx, memberId, scopeIds...)
+		if err != nil {
+			return err
+		}
+		for _, userRole := range userRoles {
+			if err := s.userRoleRepo.Delete(userRole.Id, ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return response.Failure(app_errors.NewGeneralError(app_errors.DatabaseError))
+	}
+	return response.Success(true)
+}
+
+func (s *OrganizationService) CreateTeam(ctx context.Context, userId uuid.UUID, organizationId uuid.UUID, request requests.CreateTeamRequest) *response.Response[*responses.TeamResponse] {
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		return response.FailureWithData[*responses.TeamResponse](nil, app_errors.NewGeneralError(app_errors.DataInvalid))
+	}
+	if _, _, appErr := s.authorize(ctx, organizationId, userId, true); appErr != nil {
+		return response.FailureWithData[*responses.TeamResponse](nil, appErr)
+	}
+
+	team := &entities.Team{
+		BaseAuditTrackingEntity: entity.NewSQLModel(),
+		OrganizationId:          organizationId,
+		Name:                    name,
+	}
+	if _, err := s.teamRepo.Create(team, ctx); err != nil {
+		return response.FailureWithData[*responses.TeamResponse](nil, app_errors.NewGeneralError(app_errors.DatabaseError))
+	}
+	return response.Success(toTeamResponse(team))
+}
+
+func (s *OrganizationService) ListTeams(ctx context.Context, userId uuid.UUID, organizationId uuid.UUID) *response.Response[[]*responses.TeamResponse] {
+	if _, _, appErr := s.authorize(ctx, organizationId, userId, false); appErr != nil {
+		return response.FailureWithData[[]*responses.TeamResponse](nil, appErr)
+	}
+	teams, err := s.teamRepo.FindByOrganization(ctx, organizationId)
+	if err != nil {
+		return response.FailureWithData[[]*responses.TeamResponse](nil, app_errors.NewGeneralError(app_errors.DatabaseError))
+	}
+	result := make([]*responses.TeamResponse, 0, len(teams))
+	for i := range teams {
+		result = append(result, toTeamResponse(&teams[i]))
+	}
+	return response.Success(result)
+}
+
+// AddTeamMember grants a member of the organization the team member role
+func (s *OrganizationService) AddTeamMember(ctx context.Context, userId uuid.UUID, organizationId uuid.UUID, teamId uuid.UUID, request requests.AddTeamMemberRequest) *response.Response[bool] {
+	if _, _, appErr := s.authorize(ctx, organizationId, userId, true); appErr != nil {
+		return response.Failure(appErr)
+	}
+
+	team, err := s.teamRepo.GetByID(teamId, ctx)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && team.OrganizationId != organizationId) {
+		return response.Failure(identity_errors.NewIdentityError(identity_errors.TeamNotFound))
+	}
+	if err != nil {
+		return response.Failure(app_errors.NewGeneralError(app_errors.DatabaseError))
+	}
+
+	member, err := s.membershipOf(ctx, organizationId, request.UserId)
+	if err != nil {
+		return response.Failure(app_errors.NewGeneralError(app_errors.DatabaseError))
+	}
+	if member == nil {
+		return response.Failure(identity_errors.NewIdentityError(identity_errors.NotOrganizationMember))
+	}
+
+	existing, err := s.userRoleRepo.FindUserScoped(ctx, request.UserId, teamId)
+	if err != nil {
+		return response.Failure(app_errors.NewGeneralError(app_errors.DatabaseError))
+	}
+	if len(existing) > 0 {
+		return response.Success(true)
+	}
+	if err := s.grantRole(ctx, request.UserId, entities.Role_TeamMember, teamId); err != nil {
+		return response.Failure(app_errors.NewGeneralError(app_errors.DatabaseError))
+	}
+	return response.Success(true)
+}
+
+// authorize loads the organization and the membership of the user in it, manage requires the owner or admin role
+func (s *OrganizationService) authorize(ctx context.Context, organizationId uuid.UUID, userId uuid.UUID, manage bool) (*entities.Organization, *membership, app_errors.AppError) {
+	organization, err := s.organizationRepo.GetByID(organizationId, ctx)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, identity_errors.NewIdentityError(identity_errors.OrganizationNotFound)
+	}
+	if err != nil {
+		return nil, nil, app_errors.NewGeneralError(app_errors.DatabaseError)
+	}
+
+	member, err := s.membershipOf(ctx, organizationId, userId)
+	if err != nil {
+		return nil, nil, app_errors.NewGeneralError(app_errors.DatabaseError)
+	}
+	if member == nil {
+		return nil, nil, identity_errors.NewIdentityError(identity_errors.NotOrganizationMember)
+	}
+	if manage && !member.canManage() {
+		return nil, nil, identity_errors.NewIdentityError(identity_errors.OrganizationPermissionDenied)
+	}
+	return organization, member, nil
+}
+
+// membershipOf returns the organization role of the user, nil when they are not a member
+func (s *OrganizationService) membershipOf(ctx context.Context, organizationId uuid.UUID, userId uuid.UUID) (*membership, error) {
+	userRoles, err := s.userRoleRepo.FindUserScoped(ctx, userId, organizationId)
+	if err != nil {
+		return nil, err
+	}
+	for i := range userRoles {
+		role, err := s.roleRepo.GetByID(userRoles[i].RoleId.UUID, ctx)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if role.Scope == entities.RoleScope_Organization {
+			return &membership{role: role, userRole: &userRoles[i]}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *OrganizationService) grantRole(ctx context.Context, userId uuid.UUID, roleCode string, scopeId uuid.UUID) error {
+	role, err := s.roleRepo.FindByCode(ctx, roleCode)
+	if err != nil {
+		return err
+	}
+	_, err = s.userRoleRepo.Create(&entities.UserRole{
+		BaseAuditTrackingEntity: entity.NewSQLModel(),
+		UserId:                  uuid.NullUUID{UUID: userId, Valid: true},
+		RoleId:                  uuid.NullUUID{UUID: role.Id, Valid: true},
+		ScopeId:                 uuid.NullUUID{UUID: scopeId, Valid: true},
+	}, ctx)
+	return err
+}
+
+func toOrganizationResponse(organization *entities.Organization, role string) *responses.OrganizationResponse {
+	return &responses.OrganizationResponse{
+		Id:      organization.Id,
+		Name:    organization.Name,
+		OwnerId: organization.OwnerId,
+		Role:    role,
+	}
+}
+
+func toTeamResponse(team *entities.Team) *responses.TeamResponse {
+	return &responses.TeamResponse{
+		Id:             team.Id,
+		OrganizationId: team.OrganizationId,
+		Name:           team.Name,
+	}
+}
@@ -0,0 +1,28 @@
+package services
+
+import (
+	"context"
+
+	"backend/internal/infrastructures/repositories"
+	"backend/pkg/middlewares"
+
+	"github.com/google/uuid"
+)
+
+type RoleService struct {
+	userRoleRepo repositories.UserRoleRepository
+}
+
+func NewRoleService(userRoleRepo repositories.UserRoleRepository) *RoleService {
+	return &RoleService{userRoleRepo: userRoleRepo}
+}
+
+// NewRoleChecker exposes the role service to the role middleware
+func NewRoleChecker(roleService *RoleService) middlewares.RoleChecker {
+	return roleService
+}
+
+// HasRole reports whether the user holds the global role
+func (s *RoleService) HasRole(ctx context.Context, userId uuid.UUID, role string) (bool, error) {
+	return s.userRoleRepo.HasGlobalRole(ctx, userId, role)
+}
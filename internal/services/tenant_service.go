@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+
+	"backend/internal/infrastructures/entities"
+	identity_errors "backend/internal/infrastructures/errors"
+	"backend/internal/infrastructures/repositories"
+	"backend/internal/models/requests"
+	"backend/internal/models/responses"
+	"backend/pkg/database"
+	"backend/pkg/entity"
+	app_errors "backend/pkg/errors"
+	"backend/pkg/jwt_generate"
+	"backend/pkg/logger"
+	"backend/pkg/middlewares"
+	"backend/pkg/response"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var tenantSlugPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+type TenantService struct {
+	tenantRepo     repositories.TenantRepository
+	membershipRepo repositories.TenantMembershipRepository
+	unitOfWork     database.UnitOfWork
+	logger         logger.Logger
+	jwtGen         jwt_generate.JwtGenerate
+}
+
+func NewTenantService(tenantRepo repositories.TenantRepository,
+	membershipRepo repositories.TenantMembershipRepository,
+	unitOfWork database.UnitOfWork,
+	logger logger.Logger,
+	jwtGen jwt_generate.JwtGenerate,
+) *TenantService {
+	return &TenantService{tenantRepo: tenantRepo, membershipRepo: membershipRepo, unitOfWork: unitOfWork, logger: logger, jwtGen: jwtGen}
+}
+
+// NewTenantResolver exposes the tenant service to the tenant middleware
+func NewTenantResolver(tenantService *TenantService) middlewares.TenantResolver {
+	return tenantService
+}
+
+// ResolveTenant maps a tenant id or slug to the id of an active tenant
+func (s *TenantService) ResolveTenant(ctx context.Context, key string) (uuid.UUID, error) {
+	var tenant *entities.Tenant
+	var err error
+	if id, parseErr := uuid.Parse(key); parseErr == nil {
+		tenant, err = s.tenantRepo.GetByID(id, ctx)
+	} else {
+		tenant, err = s.tenantRepo.FindBySlug(ctx, strings.ToLower(key))
+	}
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if !tenant.IsActive {
+		return uuid.Nil, gorm.ErrRecordNotFound
+	}
+	return tenant.Id, nil
+}
+
+// CreateTenant creates a tenant and makes its creator a member, the first tenant of a user becomes their default
+func (s *TenantService) CreateTenant(ctx context.Context, userId uuid.UUID, request requests.CreateTenantRequest) *response.Response[*responses.TenantResponse] {
+	slug := strings.ToLower(strings.TrimSpace(request.Slug))
+	if strings.TrimSpace(request.Name) == "" || !tenantSlugPattern.MatchString(slug) {
+		return response.FailureWithData[*responses.TenantResponse](nil, app_errors.NewGeneralError(app_errors.DataInvalid))
+	}
+
+	existing, err := s.tenantRepo.FindBySlug(ctx, slug)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return response.FailureWithData[*responses.TenantResponse](nil, app_errors.NewGeneralError(app_errors.DatabaseError))
+	}
+	if existing != nil {
+		return response.FailureWithData[*responses.TenantResponse](nil, identity_errors.NewIdentityError(identity_errors.TenantSlugExisted))
+	}
+
+	memberships, err := s.membershipRepo.FindByUser(ctx, userId)
+	if err != nil {
+		return response.FailureWithData[*responses.TenantResponse](nil, app_errors.NewGeneralError(app_errors.DatabaseError))
+	}
+
+	tenant := &entities.Tenant{
+		BaseAuditTrackingEntity: entity.NewSQLModel(),
+		Name:                    strings.TrimSpace(request.Name),
+		Slug:                    slug,
+		IsActive:                true,
+	}
+	membership := &entities.TenantMembership{
+		BaseAuditTrackingEntity: entity.NewSQLModel(),
+		UserId:                  userId,
+		IsDefault:               len(memberships) == 0,
+	}
+	err = s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if _, err := s.tenantRepo.Create(tenant, ctx); err != nil {
+			return err
+		}
+		_, err := s.membershipRepo.Create(membership, database.WithTenant(ctx, tenant.Id))
+		return err
+	})
+	if err != nil {
+		return response.FailureWithData[*responses.TenantResponse](nil, app_errors.NewGeneralError(app_errors.DatabaseError))
+	}
+	return response.Success(toTenantResponse(tenant, membership))
+}
+
+// ListTenants lists the tenants the user is a member of
+func (s *TenantService) ListTenants(ctx context.Context, userId uuid.UUID) *response.Response[[]*responses.TenantResponse] {
+	memberships, err := s.membershipRepo.FindByUser(ctx, userId)
+	if err != nil {
+		return response.FailureWithData[[]*responses.TenantResponse](nil, app_errors.NewGeneralError(app_errors.DatabaseError))
+	}
+
+	result := make([]*responses.TenantResponse, 0, len(memberships))
+	for i := range memberships {
+		tenant, err := s.tenantRepo.GetByID(memberships[i].TenantId, ctx)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return response.FailureWithData[[]*responses.TenantResponse](nil, app_errors.NewGeneralError(app_errors.DatabaseError))
+		}
+		if tenant.IsActive {
+			result = append(result, toTenantResponse(tenant, &memberships[i]))
+		}
+	}
+	return response.Success(result)
+}
+
+// DefaultTenant returns the tenant a user signs in to, uuid.Nil when they have no membership
+func (s *TenantService) DefaultTenant(ctx context.Context, userId uuid.UUID) (uuid.UUID, error) {
+	memberships, err := s.membershipRepo.FindByUser(ctx, userId)
+	if err != nil || len(memberships) == 0 {
+		return uuid.Nil, err
+	}
+	for _, membership := range memberships {
+		if membership.IsDefault {
+			return membership.TenantId, nil
+		}
+	}
+	return memberships[0].TenantId, nil
+}
+
+// SwitchTenant issues an access token bound to another tenant the user is a member of
+func (s *TenantService) SwitchTenant(ctx context.Context, userId uuid.UUID, email string, tenantId uuid.UUID) *response.Response[*responses.AuthenResponse] {
+	tenantCtx := database.WithTenant(ctx, tenantId)
+	tenant, err := s.tenantRepo.GetByID(tenantId, ctx)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && !tenant.IsActive) {
+		return response.FailureWithData[*responses.AuthenResponse](nil, identity_errors.NewIdentityError(identity_errors.TenantNotFound))
+	}
+	if err != nil {
+		return response.FailureWithData[*responses.AuthenResponse](nil, app_errors.NewGeneralError(app_errors.DatabaseError))
+	}
+
+	_, err = s.membershipRepo.FindMembership(tenantCtx, userId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return response.FailureWithData[*responses.AuthenResponse](nil, identity_errors.NewIdentityError(identity_errors.NotTenantMember))
+	}
+	if err != nil {
+		return response.FailureWithData[*responses.AuthenResponse](nil, app_errors.NewGeneralError(app_errors.DatabaseError))
+	}
+
+	token, err := s.jwtGen.GenerateToken(&jwt_generate.TokenPayload{
+		UserId:   userId,
+		Email:    email,
+		TenantId: tenantId,
+	})
+	if err != nil {
+		return response.FailureWithData[*responses.AuthenResponse](nil, identity_errors.NewIdentityError(identity_errors.JWTError))
+	}
+	return response.Success(&responses.AuthenResponse{AccessToken: token})
+}
+
+func toTenantResponse(tenant *entities.Tenant, membership *entities.TenantMembership) *responses.TenantResponse {
+	return &responses.TenantResponse{
+		Id:        tenant.Id,
+		Name:      tenant.Name,
+		Slug:      tenant.Slug,
+		IsDefault: membership.IsDefault,
+	}
+}
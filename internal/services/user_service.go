@@ -1,39 +1,101 @@
 package services
 
 import (
+	"backend/email_template"
+	"backend/internal/infrastructures/entities"
 	identity_errors "backend/internal/infrastructures/errors"
 	"backend/internal/infrastructures/repositories"
+	"backend/internal/models/requests"
 	"backend/internal/models/responses"
+	"backend/pkg/cache"
+	"backend/pkg/database"
 	app_errors "backend/pkg/errors"
 	"backend/pkg/logger"
 	"backend/pkg/response"
 	"context"
+	"errors"
+	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
+const userCacheTTL = 10 * time.Minute
+
+type UserCache = cache.TypedCache[*responses.UserResponse]
+
 type UserService struct {
-	userRepo repositories.UserRepository
-	logger   logger.Logger
+	userRepo  repositories.UserRepository
+	userCache *UserCache
+	logger    logger.Logger
+}
+
+func NewUserService(userRepo repositories.UserRepository, userCache *UserCache, logger logger.Logger) *UserService {
+	return &UserService{userRepo: userRepo, userCache: userCache, logger: logger}
 }
 
-func NewUserService(userRepo repositories.UserRepository, logger logger.Logger) *UserService {
-	return &UserService{userRepo: userRepo, logger: logger}
+// NewUserCache caches the user profiles served by GetUser, anything saving a user must invalidate its entry
+func NewUserCache(redisCache cache.Cache) *UserCache {
+	return cache.NewTypedCache[*responses.UserResponse](redisCache, cache.JSONCodec{})
 }
 
 func (s *UserService) GetUser(id uuid.UUID, ctx context.Context) *response.Response[*responses.UserResponse] {
-	user, err := s.userRepo.GetByID(id, ctx)
+	user, err := s.userCache.GetOrLoad(ctx, cache.UserKey.Build(id), userCacheTTL, func(ctx context.Context) (*responses.UserResponse, error) {
+		user, err := s.userRepo.GetByID(id, ctx)
+		if err != nil {
+			return nil, err
+		}
+		return toUserResponse(user), nil
+	}, cache.UserTag(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return response.FailureWithData[*responses.UserResponse](nil, identity_errors.NewIdentityError(identity_errors.UserNotFound))
+	}
 	if err != nil {
 		return response.FailureWithData[*responses.UserResponse](nil, app_errors.NewGeneralError(app_errors.DatabaseError))
 	}
-	if user == nil {
+	return response.Success(user)
+}
+
+// UpdateUser saves the profile, version is the one the client read and is checked against the stored row when given
+func (s *UserService) UpdateUser(id uuid.UUID, version *int64, request requests.UpdateUserRequest, ctx context.Context) *response.Response[*responses.UserResponse] {
+	ctx = database.WithPrimary(ctx)
+	user, err := s.userRepo.GetByID(id, ctx)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return response.FailureWithData[*responses.UserResponse](nil, identity_errors.NewIdentityError(identity_errors.UserNotFound))
 	}
-	return response.Success(&responses.UserResponse{
+	if err != nil {
+		return response.FailureWithData[*responses.UserResponse](nil, app_errors.NewGeneralError(app_errors.DatabaseError))
+	}
+	if version != nil {
+		user.Version = *version
+	}
+
+	user.FirstName = request.FirstName
+	user.LastName = request.LastName
+	user.Avatar = request.Avatar
+	user.DateOfBirth = request.DateOfBirth
+	user.Locale = email_template.NormalizeLocale(request.Locale)
+
+	if err = s.userRepo.Update(user, ctx); err != nil {
+		if errors.Is(err, database.ErrConcurrencyConflict) {
+			return response.FailureWithData[*responses.UserResponse](nil, app_errors.NewGeneralError(app_errors.DataConflict))
+		}
+		return response.FailureWithData[*responses.UserResponse](nil, app_errors.NewGeneralError(app_errors.DatabaseError))
+	}
+	if err := s.userCache.Delete(ctx, cache.UserKey.Build(id)); err != nil {
+		s.logger.WithContext(ctx).Error("Cant not invalidate user cache")
+	}
+	return response.Success(toUserResponse(user))
+}
+
+func toUserResponse(user *entities.User) *responses.UserResponse {
+	return &responses.UserResponse{
 		Id:          user.Id,
 		Email:       user.Email,
 		FullName:    user.FullName(),
 		Avatar:      user.Avatar,
 		DateOfBirth: user.DateOfBirth,
-	})
+		Locale:      user.Locale,
+		Version:     user.Version,
+	}
 }
@@ -1,10 +1,14 @@
 package cache
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type Cache interface {
 	Connect(ctx context.Context) error
 	Get(ctx context.Context, key string) (string, error)
-	Set(ctx context.Context, key string, value string, ttl int) error
+	// Set stores value for ttl, a ttl of zero keeps it until it is deleted
+	Set(ctx context.Context, key string, value string, ttl time.Duration) error
 	Delete(ctx context.Context, key string) error
 }
@@ -0,0 +1,34 @@
+package cache
+
+import (
+	"encoding/json"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Codec turns the values of a TypedCache into the bytes stored in the underlying Cache
+type Codec interface {
+	Marshal(value any) ([]byte, error)
+	Unmarshal(data []byte, value any) error
+}
+
+type JSONCodec struct{}
+
+func (JSONCodec) Marshal(value any) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+func (JSONCodec) Unmarshal(data []byte, value any) error {
+	return json.Unmarshal(data, value)
+}
+
+// MsgpackCodec is more compact than JSON, at the cost of values no longer being readable in redis-cli
+type MsgpackCodec struct{}
+
+func (MsgpackCodec) Marshal(value any) ([]byte, error) {
+	return msgpack.Marshal(value)
+}
+
+func (MsgpackCodec) Unmarshal(data []byte, value any) error {
+	return msgpack.Unmarshal(data, value)
+}
@@ -0,0 +1,35 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Invalidator drops groups of keys at once
+type Invalidator interface {
+	// Tag adds key to tags, a tag is kept at least as long as its longest lived key
+	Tag(ctx context.Context, key string, ttl time.Duration, tags ...string) error
+	// InvalidateTag deletes every key of tag along with the tag, it returns the number of keys deleted
+	InvalidateTag(ctx context.Context, tag string) (int, error)
+	// Purge deletes every key matching the glob pattern, the pattern is relative to the key prefix
+	Purge(ctx context.Context, pattern string) (int, error)
+}
+
+// NewInvalidator returns the invalidator of the configured cache
+func NewInvalidator(c Cache) (Invalidator, error) {
+	invalidator, ok := c.(Invalidator)
+	if !ok {
+		return nil, fmt.Errorf("cache %T does not support invalidation", c)
+	}
+	return invalidator, nil
+}
+
+// Tag adds key to tags when c supports tags
+func Tag(ctx context.Context, c Cache, key string, ttl time.Duration, tags ...string) error {
+	invalidator, ok := c.(Invalidator)
+	if !ok || len(tags) == 0 {
+		return nil
+	}
+	return invalidator.Tag(ctx, key, ttl, tags...)
+}
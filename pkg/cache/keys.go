@@ -0,0 +1,106 @@
+package cache
+
+import (
+	configs "backend/pkg/config"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Keys of every feature are declared here so their names and prefixes stay consistent
+var (
+	ConfirmAccountKey = NewKeyTemplate[uuid.UUID]("confirm_account", "identity:otp_code:%s:email_confirm")
+	RefreshTokenKey   = NewKeyTemplate[uuid.UUID]("refresh_token", "identity:refresh_token:%s")
+	ForgotPasswordKey = NewKeyTemplate[uuid.UUID]("forgot_password", "identity:forgot_password:%s")
+	UserKey           = NewKeyTemplate[uuid.UUID]("user", "identity:user:%s")
+	RateLimitKey      = NewKeyTemplate[string]("rate_limit", "ratelimit:%s")
+	LockKey           = NewKeyTemplate[string]("lock", "lock:{%s}")
+	LockFenceKey      = NewKeyTemplate[string]("lock_fence", "lock:{%s}:fence")
+	TagKey            = NewKeyTemplate[string]("tag", "tag:%s")
+)
+
+// UserTag groups the keys holding data of a user, InvalidateTag(ctx, UserTag(id)) clears all of them
+func UserTag(userId uuid.UUID) string {
+	return fmt.Sprintf("user:%s", userId)
+}
+
+var formatVerb = regexp.MustCompile(`%[a-zA-Z]`)
+
+var keys = &keyRegistry{templates: make(map[string]string)}
+
+type keyRegistry struct {
+	mu        sync.RWMutex
+	prefix    string
+	templates map[string]string
+}
+
+// RegisterKeys prefixes every key with the service name and mode, so services and environments can share a Redis
+func RegisterKeys(appConfig *configs.AppConfig) {
+	parts := []string{}
+	for _, part := range []string{appConfig.Server.ServiceName, appConfig.Server.Mode} {
+		if part = strings.ToLower(strings.TrimSpace(part)); part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	keys.mu.Lock()
+	defer keys.mu.Unlock()
+	keys.prefix = strings.Join(parts, ":")
+}
+
+// Prefixed qualifies key with the registered prefix
+func Prefixed(key string) string {
+	keys.mu.RLock()
+	defer keys.mu.RUnlock()
+	if keys.prefix == "" {
+		return key
+	}
+	return keys.prefix + ":" + key
+}
+
+// KeyTemplates lists the registered templates by name with their Pattern
+func KeyTemplates() map[string]string {
+	keys.mu.RLock()
+	defer keys.mu.RUnlock()
+	templates := make(map[string]string, len(keys.templates))
+	for name, format := range keys.templates {
+		templates[name] = pattern(format)
+	}
+	return templates
+}
+
+// KeyTemplate builds the keys of one kind from an argument of type T
+type KeyTemplate[T any] struct {
+	name   string
+	format string
+}
+
+func NewKeyTemplate[T any](name string, format string) KeyTemplate[T] {
+	keys.mu.Lock()
+	defer keys.mu.Unlock()
+	if _, ok := keys.templates[name]; ok {
+		panic(fmt.Sprintf("cache key template %s is registered twice", name))
+	}
+	keys.templates[name] = format
+	return KeyTemplate[T]{name: name, format: format}
+}
+
+func (k KeyTemplate[T]) Name() string {
+	return k.name
+}
+
+func (k KeyTemplate[T]) Build(arg T) string {
+	return Prefixed(fmt.Sprintf(k.format, arg))
+}
+
+// Pattern matches every key of the template, it is relative to the prefix as Purge expects
+func (k KeyTemplate[T]) Pattern() string {
+	return pattern(k.format)
+}
+
+func pattern(format string) string {
+	return formatVerb.ReplaceAllString(format, "*")
+}
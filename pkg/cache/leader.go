@@ -0,0 +1,129 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/fx"
+)
+
+const defaultLeaderTTL = 15 * time.Second
+
+// LeaderTask runs while this instance leads, ctx is cancelled when leadership is lost.
+// token is the fencing token of the leadership term.
+type LeaderTask func(ctx context.Context, token int64)
+
+// LeaderElection campaigns for a lock shared by all replicas so tasks run on exactly one of them
+type LeaderElection struct {
+	locker Locker
+	key    string
+	ttl    time.Duration
+	logger Logger
+	tasks  []LeaderTask
+	leader atomic.Bool
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewLeaderElection campaigns from app start to app stop, register tasks with Go before the app starts.
+// The lock key carries the service prefix, so each service and environment elects its own leader.
+func NewLeaderElection(lc fx.Lifecycle, locker Locker, logger Logger) *LeaderElection {
+	election := &LeaderElection{
+		locker: locker,
+		key:    "leader",
+		ttl:    defaultLeaderTTL,
+		logger: logger,
+	}
+	lc.Append(fx.Hook{
+		OnStart: func(_ context.Context) error {
+			election.start()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			return election.stop(ctx)
+		},
+	})
+	return election
+}
+
+func (e *LeaderElection) Go(task LeaderTask) {
+	e.tasks = append(e.tasks, task)
+}
+
+// Fenced fails with ErrLockFenced once another term began after the one token belongs to, tasks check it before each write
+func (e *LeaderElection) Fenced(ctx context.Context, token int64) error {
+	return e.locker.Fenced(ctx, e.key, token)
+}
+
+func (e *LeaderElection) IsLeader() bool {
+	return e.leader.Load()
+}
+
+func (e *LeaderElection) start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+	e.done = make(chan struct{})
+	go e.campaign(ctx)
+}
+
+func (e *LeaderElection) stop(ctx context.Context) error {
+	if e.cancel == nil {
+		return nil
+	}
+	e.cancel()
+	select {
+	case <-e.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (e *LeaderElection) campaign(ctx context.Context) {
+	defer close(e.done)
+	for {
+		lock, err := e.locker.TryLock(ctx, e.key, e.ttl)
+		if err == nil {
+			e.lead(ctx, lock)
+		} else if !errors.Is(err, ErrLockNotAcquired) && ctx.Err() == nil {
+			e.logger.Warnf("leader election %s: %v", e.key, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(e.ttl / 3):
+		}
+	}
+}
+
+func (e *LeaderElection) lead(ctx context.Context, lock *Lock) {
+	term, cancel := context.WithCancel(ctx)
+	var tasks sync.WaitGroup
+	e.leader.Store(true)
+	for _, task := range e.tasks {
+		tasks.Add(1)
+		go func(task LeaderTask) {
+			defer tasks.Done()
+			task(term, lock.Token())
+		}(task)
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-lock.Lost():
+		e.logger.Warnf("leader election %s: leadership lost", e.key)
+	}
+	cancel()
+	tasks.Wait()
+	e.leader.Store(false)
+
+	release, cancelRelease := context.WithTimeout(context.Background(), e.ttl/3)
+	defer cancelRelease()
+	if err := lock.Unlock(release); err != nil {
+		e.logger.Warnf("leader election %s: %v", e.key, err)
+	}
+}
@@ -0,0 +1,161 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrLockNotAcquired = errors.New("lock is held by another owner")
+	ErrLockFenced      = errors.New("lock was acquired again since this token was handed out")
+)
+
+// Locker hands out exclusive, expiring locks. Each acquisition gets a fencing token that grows with every
+// acquisition of the same key, so writers can reject work from an owner whose lock already expired.
+type Locker interface {
+	// Lock waits until the lock is acquired or ctx is done
+	Lock(ctx context.Context, key string, ttl time.Duration) (*Lock, error)
+	// TryLock fails with ErrLockNotAcquired when the lock is held
+	TryLock(ctx context.Context, key string, ttl time.Duration) (*Lock, error)
+	// Fenced fails with ErrLockFenced once a later acquisition of key superseded token. Holders check it right
+	// before each guarded write, an owner that was paused past its ttl then stops instead of racing the next one.
+	Fenced(ctx context.Context, key string, token int64) error
+}
+
+// lockBackend performs the atomic steps of a lock, value identifies the owner
+type lockBackend interface {
+	acquire(ctx context.Context, key string, value string, ttl time.Duration) (int64, bool, error)
+	extend(ctx context.Context, key string, value string, ttl time.Duration) (bool, error)
+	release(ctx context.Context, key string, value string) error
+	// fence returns the latest token handed out for key
+	fence(ctx context.Context, key string) (int64, error)
+}
+
+// Lock is renewed in the background until Unlock, Lost is closed when renewal fails and ownership can no longer be assumed
+type Lock struct {
+	key     string
+	value   string
+	token   int64
+	ttl     time.Duration
+	backend lockBackend
+	lost    chan struct{}
+	stop    chan struct{}
+	stopped sync.Once
+	renewal sync.WaitGroup
+}
+
+func (l *Lock) Key() string {
+	return l.key
+}
+
+// Token is the fencing token of this acquisition
+func (l *Lock) Token() int64 {
+	return l.token
+}
+
+func (l *Lock) Lost() <-chan struct{} {
+	return l.lost
+}
+
+func (l *Lock) Unlock(ctx context.Context) error {
+	l.stopped.Do(func() { close(l.stop) })
+	l.renewal.Wait()
+	return l.backend.release(ctx, l.key, l.value)
+}
+
+func (l *Lock) renew() {
+	defer l.renewal.Done()
+
+	interval := l.ttl / 3
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	renewedAt := time.Now()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			owned, err := l.backend.extend(ctx, l.key, l.value, l.ttl)
+			cancel()
+			if err == nil && owned {
+				renewedAt = time.Now()
+				continue
+			}
+			// a transient error is retried while the lock can still be valid
+			if (err == nil && !owned) || time.Since(renewedAt) >= l.ttl {
+				close(l.lost)
+				return
+			}
+		}
+	}
+}
+
+func tryLock(ctx context.Context, backend lockBackend, key string, ttl time.Duration) (*Lock, error) {
+	if ttl <= 0 {
+		return nil, fmt.Errorf("lock ttl must be positive")
+	}
+	value := uuid.NewString()
+	token, acquired, err := backend.acquire(ctx, key, value, ttl)
+	if err != nil {
+		return nil, err
+	}
+	if !acquired {
+		return nil, ErrLockNotAcquired
+	}
+
+	lock := &Lock{
+		key:     key,
+		value:   value,
+		token:   token,
+		ttl:     ttl,
+		backend: backend,
+		lost:    make(chan struct{}),
+		stop:    make(chan struct{}),
+	}
+	lock.renewal.Add(1)
+	go lock.renew()
+	return lock, nil
+}
+
+func checkFence(ctx context.Context, backend lockBackend, key string, token int64) error {
+	latest, err := backend.fence(ctx, key)
+	if err != nil {
+		return err
+	}
+	if latest > token {
+		return ErrLockFenced
+	}
+	return nil
+}
+
+func waitLock(ctx context.Context, backend lockBackend, key string, ttl time.Duration) (*Lock, error) {
+	retry := min(ttl/10, time.Second)
+	for {
+		lock, err := tryLock(ctx, backend, key, ttl)
+		if !errors.Is(err, ErrLockNotAcquired) {
+			return lock, err
+		}
+		jitter := time.Duration(rand.Int63n(int64(retry)/2 + 1))
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(retry/2 + jitter):
+		}
+	}
+}
+
+// lockKey and fenceKey share a hash tag so the lock scripts touch a single cluster slot
+func lockKey(key string) string {
+	return LockKey.Build(key)
+}
+
+func fenceKey(key string) string {
+	return LockFenceKey.Build(key)
+}
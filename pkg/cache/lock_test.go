@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/fx/fxtest"
+)
+
+type testLogger struct {
+	t *testing.T
+}
+
+func (l testLogger) Warnf(format string, args ...any) {
+	l.t.Logf(format, args...)
+}
+
+func TestLockTokensGrowWithEveryAcquisition(t *testing.T) {
+	ctx := context.Background()
+	locker := NewMemoryCache(10)
+
+	first, err := locker.TryLock(ctx, "job", time.Second)
+	if err != nil {
+		t.Fatalf("first lock: %v", err)
+	}
+	if _, err := locker.TryLock(ctx, "job", time.Second); !errors.Is(err, ErrLockNotAcquired) {
+		t.Fatalf("second lock while held: %v", err)
+	}
+	if err := first.Unlock(ctx); err != nil {
+		t.Fatalf("unlock: %v", err)
+	}
+
+	second, err := locker.TryLock(ctx, "job", time.Second)
+	if err != nil {
+		t.Fatalf("second lock: %v", err)
+	}
+	defer second.Unlock(ctx)
+	if second.Token() <= first.Token() {
+		t.Fatalf("token %d does not grow past %d", second.Token(), first.Token())
+	}
+}
+
+func TestFencedRejectsSupersededToken(t *testing.T) {
+	ctx := context.Background()
+	locker := NewMemoryCache(10)
+
+	stale, err := locker.TryLock(ctx, "job", time.Second)
+	if err != nil {
+		t.Fatalf("first lock: %v", err)
+	}
+	if err := locker.Fenced(ctx, "job", stale.Token()); err != nil {
+		t.Fatalf("current token is fenced: %v", err)
+	}
+	// the first owner lost the lock, say it was paused past its ttl, and another owner took it
+	_ = stale.Unlock(ctx)
+	current, err := locker.TryLock(ctx, "job", time.Second)
+	if err != nil {
+		t.Fatalf("second lock: %v", err)
+	}
+	defer current.Unlock(ctx)
+
+	if err := locker.Fenced(ctx, "job", stale.Token()); !errors.Is(err, ErrLockFenced) {
+		t.Fatalf("stale token: %v, want ErrLockFenced", err)
+	}
+	if err := locker.Fenced(ctx, "job", current.Token()); err != nil {
+		t.Fatalf("current token is fenced: %v", err)
+	}
+}
+
+func TestLeaderTaskTokenIsFencedAfterTheTermEnds(t *testing.T) {
+	locker := NewMemoryCache(10)
+	lc := fxtest.NewLifecycle(t)
+	election := NewLeaderElection(lc, locker, testLogger{t: t})
+
+	tokens := make(chan int64, 1)
+	election.Go(func(ctx context.Context, token int64) {
+		tokens <- token
+		<-ctx.Done()
+	})
+	lc.RequireStart()
+
+	var token int64
+	select {
+	case token = <-tokens:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the task never ran")
+	}
+	if err := election.Fenced(context.Background(), token); err != nil {
+		t.Fatalf("token of the running term is fenced: %v", err)
+	}
+	lc.RequireStop()
+
+	// another replica wins the next term
+	next, err := locker.TryLock(context.Background(), "leader", time.Second)
+	if err != nil {
+		t.Fatalf("next term: %v", err)
+	}
+	defer next.Unlock(context.Background())
+	if err := election.Fenced(context.Background(), token); !errors.Is(err, ErrLockFenced) {
+		t.Fatalf("token of the ended term: %v, want ErrLockFenced", err)
+	}
+}
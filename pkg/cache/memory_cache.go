@@ -0,0 +1,233 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"path"
+	"sync"
+	"time"
+)
+
+const defaultMemoryMaxEntries = 10000
+
+// MemoryCache is an in-process LRU cache, entries expire lazily when read or evicted by recency once full
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    *list.List
+	items      map[string]*list.Element
+	locks      map[string]memoryLock
+	fences     map[string]int64
+	tags       map[string]map[string]struct{}
+}
+
+// memoryLock is kept apart from the entries so a lock is never evicted
+type memoryLock struct {
+	value     string
+	expiresAt time.Time
+}
+
+type memoryEntry struct {
+	key       string
+	value     string
+	expiresAt time.Time
+}
+
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	if maxEntries <= 0 {
+		maxEntries = defaultMemoryMaxEntries
+	}
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		entries:    list.New(),
+		items:      make(map[string]*list.Element),
+		locks:      make(map[string]memoryLock),
+		fences:     make(map[string]int64),
+		tags:       make(map[string]map[string]struct{}),
+	}
+}
+
+func (m *MemoryCache) Connect(ctx context.Context) error {
+	return nil
+}
+
+func (m *MemoryCache) Get(ctx context.Context, key string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	element, ok := m.items[key]
+	if !ok {
+		return "", nil
+	}
+	entry := element.Value.(*memoryEntry)
+	if entry.expired(time.Now()) {
+		m.remove(element)
+		return "", nil
+	}
+	m.entries.MoveToFront(element)
+	return entry.value, nil
+}
+
+func (m *MemoryCache) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+
+	if element, ok := m.items[key]; ok {
+		entry := element.Value.(*memoryEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		m.entries.MoveToFront(element)
+		return nil
+	}
+
+	m.items[key] = m.entries.PushFront(&memoryEntry{key: key, value: value, expiresAt: expiresAt})
+	for m.entries.Len() > m.maxEntries {
+		m.remove(m.entries.Back())
+	}
+	return nil
+}
+
+func (m *MemoryCache) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if element, ok := m.items[key]; ok {
+		m.remove(element)
+	}
+	return nil
+}
+
+// Flush drops every entry
+func (m *MemoryCache) Flush() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries.Init()
+	m.items = make(map[string]*list.Element)
+	m.tags = make(map[string]map[string]struct{})
+}
+
+// Tag records key under tags, keys gone from the cache are pruned from the tag as it grows
+func (m *MemoryCache) Tag(ctx context.Context, key string, ttl time.Duration, tags ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, tag := range tags {
+		members, ok := m.tags[tag]
+		if !ok {
+			members = make(map[string]struct{})
+			m.tags[tag] = members
+		}
+		for member := range members {
+			if _, ok := m.items[member]; !ok {
+				delete(members, member)
+			}
+		}
+		members[key] = struct{}{}
+	}
+	return nil
+}
+
+func (m *MemoryCache) InvalidateTag(ctx context.Context, tag string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	deleted := 0
+	for key := range m.tags[tag] {
+		if element, ok := m.items[key]; ok {
+			m.remove(element)
+			deleted++
+		}
+	}
+	delete(m.tags, tag)
+	return deleted, nil
+}
+
+func (m *MemoryCache) Purge(ctx context.Context, pattern string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	match := Prefixed(pattern)
+	if _, err := path.Match(match, ""); err != nil {
+		return 0, fmt.Errorf("invalid purge pattern %s: %w", pattern, err)
+	}
+	deleted := 0
+	for key, element := range m.items {
+		if matched, _ := path.Match(match, key); matched {
+			m.remove(element)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (m *MemoryCache) remove(element *list.Element) {
+	m.entries.Remove(element)
+	delete(m.items, element.Value.(*memoryEntry).key)
+}
+
+func (e *memoryEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && now.After(e.expiresAt)
+}
+
+// Lock and TryLock only exclude callers within this process
+
+func (m *MemoryCache) Lock(ctx context.Context, key string, ttl time.Duration) (*Lock, error) {
+	return waitLock(ctx, m, key, ttl)
+}
+
+func (m *MemoryCache) TryLock(ctx context.Context, key string, ttl time.Duration) (*Lock, error) {
+	return tryLock(ctx, m, key, ttl)
+}
+
+func (m *MemoryCache) Fenced(ctx context.Context, key string, token int64) error {
+	return checkFence(ctx, m, key, token)
+}
+
+func (m *MemoryCache) acquire(ctx context.Context, key string, value string, ttl time.Duration) (int64, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if lock, ok := m.locks[key]; ok && now.Before(lock.expiresAt) {
+		return 0, false, nil
+	}
+	m.locks[key] = memoryLock{value: value, expiresAt: now.Add(ttl)}
+	m.fences[key]++
+	return m.fences[key], true, nil
+}
+
+func (m *MemoryCache) extend(ctx context.Context, key string, value string, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	lock, ok := m.locks[key]
+	if !ok || lock.value != value || !now.Before(lock.expiresAt) {
+		return false, nil
+	}
+	m.locks[key] = memoryLock{value: value, expiresAt: now.Add(ttl)}
+	return true, nil
+}
+
+func (m *MemoryCache) fence(ctx context.Context, key string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.fences[key], nil
+}
+
+func (m *MemoryCache) release(ctx context.Context, key string, value string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if lock, ok := m.locks[key]; ok && lock.value == value {
+		delete(m.locks, key)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package cache
+
+import (
+	configs "backend/pkg/config"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/fx"
+)
+
+const (
+	DRIVER_REDIS  = "redis"
+	DRIVER_MEMORY = "memory"
+	DRIVER_TIERED = "tiered"
+)
+
+// Logger receives the cache's background errors, such as a lost invalidation subscription
+type Logger interface {
+	Warnf(format string, args ...any)
+}
+
+// NewCache builds the cache implementation selected by the cache driver and ties its connections to the app lifecycle
+func NewCache(lc fx.Lifecycle, appConfig *configs.AppConfig, logger Logger) (Cache, error) {
+	config := appConfig.Cache
+	switch config.Driver {
+	case DRIVER_MEMORY:
+		return NewMemoryCache(config.MaxEntries), nil
+	case DRIVER_TIERED:
+		redisCache, err := NewRedisClient(appConfig)
+		if err != nil {
+			return nil, err
+		}
+		tiered := NewTieredCache(NewMemoryCache(config.MaxEntries), redisCache,
+			time.Duration(config.L1TTL)*time.Second, config.InvalidationChannel, logger)
+		subscription, cancel := context.WithCancel(context.Background())
+		lc.Append(fx.Hook{
+			OnStart: func(_ context.Context) error {
+				tiered.Subscribe(subscription)
+				return nil
+			},
+			OnStop: func(_ context.Context) error {
+				cancel()
+				_ = tiered.Close()
+				return redisCache.Disconnect()
+			},
+		})
+		return tiered, nil
+	case DRIVER_REDIS, "":
+		redisCache, err := NewRedisClient(appConfig)
+		if err != nil {
+			return nil, err
+		}
+		lc.Append(fx.Hook{
+			OnStop: func(_ context.Context) error {
+				return redisCache.Disconnect()
+			},
+		})
+		return redisCache, nil
+	default:
+		return nil, fmt.Errorf("unknown cache driver %q", config.Driver)
+	}
+}
+
+// RedisClientOf returns the Redis client behind c, for features needing more than key/value access
+func RedisClientOf(c Cache) (redis.UniversalClient, bool) {
+	switch c := c.(type) {
+	case *RedisCache:
+		return c.client, true
+	case *TieredCache:
+		return c.l2.client, true
+	}
+	return nil, false
+}
+
+// NewLocker returns the locker of the configured cache, Redis backed caches lock across instances
+func NewLocker(c Cache) (Locker, error) {
+	locker, ok := c.(Locker)
+	if !ok {
+		return nil, fmt.Errorf("cache %T does not support locks", c)
+	}
+	return locker, nil
+}
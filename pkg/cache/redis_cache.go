@@ -3,51 +3,131 @@ package cache
 import (
 	configs "backend/pkg/config"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/go-redis/redis/v8"
 )
 
+const (
+	REDIS_MODE_STANDALONE = "standalone"
+	REDIS_MODE_SENTINEL   = "sentinel"
+	REDIS_MODE_CLUSTER    = "cluster"
+)
+
 type RedisCache struct {
-	client *redis.Client
+	client redis.UniversalClient
 }
 
-func NewRedisClient(appConfig *configs.AppConfig) Cache {
+// NewRedisClient connects to Redis and fails when it is unreachable, so a missing Redis surfaces at startup
+func NewRedisClient(appConfig *configs.AppConfig) (*RedisCache, error) {
 	config := appConfig.Redis
-	addr := fmt.Sprintf("%s:%d", config.Host, config.Port)
-	options := &redis.Options{
-		Addr:     addr,
-		DB:       0,
-		Username: config.UserName,
-		Password: config.Password,
-		PoolSize: 10,
+	options, err := universalOptions(config)
+	if err != nil {
+		return nil, err
 	}
 
-	client := redis.NewClient(options)
+	var client redis.UniversalClient
+	switch config.Mode {
+	case REDIS_MODE_STANDALONE, "":
+		client = redis.NewClient(options.Simple())
+	case REDIS_MODE_SENTINEL:
+		client = redis.NewFailoverClient(options.Failover())
+	case REDIS_MODE_CLUSTER:
+		client = redis.NewClusterClient(options.Cluster())
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	if err := client.Ping(ctx).Err(); err != nil {
-
+		_ = client.Close()
+		return nil, fmt.Errorf("failed to connect to Redis (%s) at %s: %w", redisMode(config), strings.Join(options.Addrs, ","), err)
 	}
 
 	return &RedisCache{
 		client: client,
+	}, nil
+}
+
+func universalOptions(config configs.RedisConfig) (*redis.UniversalOptions, error) {
+	options := &redis.UniversalOptions{
+		Addrs:            config.Addresses,
+		DB:               config.DB,
+		Username:         config.UserName,
+		Password:         config.Password,
+		SentinelUsername: config.SentinelUserName,
+		SentinelPassword: config.SentinelPassword,
+		MasterName:       config.MasterName,
+		PoolSize:         config.PoolSize,
+		MinIdleConns:     config.MinIdleConns,
+		DialTimeout:      config.DialTimeout,
+		ReadTimeout:      config.ReadTimeout,
+		WriteTimeout:     config.WriteTimeout,
+		PoolTimeout:      config.PoolTimeout,
+	}
+
+	switch config.Mode {
+	case REDIS_MODE_STANDALONE, "":
+		options.Addrs = []string{fmt.Sprintf("%s:%d", config.Host, config.Port)}
+	case REDIS_MODE_SENTINEL:
+		if config.MasterName == "" || len(config.Addresses) == 0 {
+			return nil, fmt.Errorf("redis sentinel mode requires masterName and sentinel addresses")
+		}
+	case REDIS_MODE_CLUSTER:
+		if len(config.Addresses) == 0 {
+			return nil, fmt.Errorf("redis cluster mode requires seed addresses")
+		}
+		if config.DB != 0 {
+			return nil, fmt.Errorf("redis cluster mode only supports db 0")
+		}
+	default:
+		return nil, fmt.Errorf("unknown redis mode %q", config.Mode)
+	}
+
+	if config.TLS.Enable {
+		tlsConfig, err := redisTLSConfig(config.TLS)
+		if err != nil {
+			return nil, err
+		}
+		options.TLSConfig = tlsConfig
 	}
+	return options, nil
 }
 
-func (r *RedisCache) Connect(ctx context.Context) error {
-	if err := r.client.Ping(ctx).Err(); err != nil {
-		return fmt.Errorf("failed to connect to Redis: %w", err)
+func redisTLSConfig(config configs.RedisTLSConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         config.ServerName,
+		InsecureSkipVerify: config.InsecureSkipVerify,
 	}
-	return nil
+	if config.CAFile != "" {
+		ca, err := os.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read redis CA bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("redis CA bundle %s has no certificates", config.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, nil
 }
 
-func (r *RedisCache) SelectDatabase(ctx context.Context, db int) error {
+func redisMode(config configs.RedisConfig) string {
+	if config.Mode == "" {
+		return REDIS_MODE_STANDALONE
+	}
+	return config.Mode
+}
 
-	if err := r.client.Do(ctx, "SELECT", db).Err(); err != nil {
-		return fmt.Errorf("failed to select database %d: %w", db, err)
+func (r *RedisCache) Connect(ctx context.Context) error {
+	if err := r.client.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("failed to connect to Redis: %w", err)
 	}
 	return nil
 }
@@ -62,9 +142,29 @@ func (r *RedisCache) Get(ctx context.Context, key string) (string, error) {
 	return val, nil
 }
 
-// ttl: seconds
-func (r *RedisCache) Set(ctx context.Context, key string, value string, ttl int) error {
-	if err := r.client.Set(ctx, key, value, time.Duration(ttl)*time.Second).Err(); err != nil {
+// getWithTTL reads key along with its remaining ttl, zero when it has no expiry
+func (r *RedisCache) getWithTTL(ctx context.Context, key string) (string, time.Duration, error) {
+	pipe := r.client.Pipeline()
+	get := pipe.Get(ctx, key)
+	pttl := pipe.PTTL(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return "", 0, fmt.Errorf("error getting key %s: %w", key, err)
+	}
+	val, err := get.Result()
+	if err == redis.Nil {
+		return "", 0, nil
+	} else if err != nil {
+		return "", 0, fmt.Errorf("error getting key %s: %w", key, err)
+	}
+	ttl := pttl.Val()
+	if ttl < 0 {
+		ttl = 0
+	}
+	return val, ttl, nil
+}
+
+func (r *RedisCache) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	if err := r.client.Set(ctx, key, value, ttl).Err(); err != nil {
 		return fmt.Errorf("error setting key %s: %w", key, err)
 	}
 	return nil
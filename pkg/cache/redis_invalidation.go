@@ -0,0 +1,139 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const scanBatch = 500
+
+// tagScript adds ARGV[2..] to the tag set and keeps it alive for ARGV[1] ms at least, 0 keeps it forever
+var tagScript = redis.NewScript(`
+local existed = redis.call('EXISTS', KEYS[1])
+redis.call('SADD', KEYS[1], unpack(ARGV, 2))
+local ttl = tonumber(ARGV[1])
+if ttl <= 0 then
+	redis.call('PERSIST', KEYS[1])
+	return 1
+end
+local current = redis.call('PTTL', KEYS[1])
+if existed == 0 or (current >= 0 and current < ttl) then
+	redis.call('PEXPIRE', KEYS[1], ttl)
+end
+return 1
+`)
+
+func (r *RedisCache) Tag(ctx context.Context, key string, ttl time.Duration, tags ...string) error {
+	for _, tag := range tags {
+		if err := tagScript.Run(ctx, r.client, []string{TagKey.Build(tag)}, ttl.Milliseconds(), key).Err(); err != nil {
+			return fmt.Errorf("error tagging key %s with %s: %w", key, tag, err)
+		}
+	}
+	return nil
+}
+
+func (r *RedisCache) InvalidateTag(ctx context.Context, tag string) (int, error) {
+	members, err := r.tagMembers(ctx, tag)
+	if err != nil {
+		return 0, err
+	}
+	if err := r.deleteKeys(ctx, append(members, TagKey.Build(tag))); err != nil {
+		return 0, err
+	}
+	return len(members), nil
+}
+
+func (r *RedisCache) Purge(ctx context.Context, pattern string) (int, error) {
+	match := Prefixed(pattern)
+	// ForEachMaster runs one goroutine per master, they all add to the count
+	var deleted atomic.Int64
+	purge := func(ctx context.Context, client redis.UniversalClient) error {
+		iter := client.Scan(ctx, 0, match, scanBatch).Iterator()
+		batch := make([]string, 0, scanBatch)
+		for iter.Next(ctx) {
+			batch = append(batch, iter.Val())
+			if len(batch) == scanBatch {
+				if err := r.deleteKeys(ctx, batch); err != nil {
+					return err
+				}
+				deleted.Add(int64(len(batch)))
+				batch = batch[:0]
+			}
+		}
+		if err := iter.Err(); err != nil {
+			return err
+		}
+		if err := r.deleteKeys(ctx, batch); err != nil {
+			return err
+		}
+		deleted.Add(int64(len(batch)))
+		return nil
+	}
+
+	var err error
+	if cluster, ok := r.client.(*redis.ClusterClient); ok {
+		err = cluster.ForEachMaster(ctx, func(ctx context.Context, client *redis.Client) error {
+			return purge(ctx, client)
+		})
+	} else {
+		err = purge(ctx, r.client)
+	}
+	if err != nil {
+		return int(deleted.Load()), fmt.Errorf("error purging keys %s: %w", match, err)
+	}
+	return int(deleted.Load()), nil
+}
+
+func (r *RedisCache) tagMembers(ctx context.Context, tag string) ([]string, error) {
+	members, err := r.client.SMembers(ctx, TagKey.Build(tag)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("error reading tag %s: %w", tag, err)
+	}
+	return members, nil
+}
+
+// deleteKeys unlinks keys one command each, so keys of different cluster slots can be deleted together
+func (r *RedisCache) deleteKeys(ctx context.Context, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	pipe := r.client.Pipeline()
+	for _, key := range keys {
+		pipe.Unlink(ctx, key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("error deleting keys: %w", err)
+	}
+	return nil
+}
+
+func (t *TieredCache) Tag(ctx context.Context, key string, ttl time.Duration, tags ...string) error {
+	return t.l2.Tag(ctx, key, ttl, tags...)
+}
+
+func (t *TieredCache) InvalidateTag(ctx context.Context, tag string) (int, error) {
+	members, err := t.l2.tagMembers(ctx, tag)
+	if err != nil {
+		return 0, err
+	}
+	if err := t.l2.deleteKeys(ctx, append(members, TagKey.Build(tag))); err != nil {
+		return 0, err
+	}
+	for _, key := range members {
+		_ = t.l1.Delete(ctx, key)
+		t.publish(ctx, key)
+	}
+	return len(members), nil
+}
+
+// Purge flushes L1 of every instance as the purged keys are not known upfront
+func (t *TieredCache) Purge(ctx context.Context, pattern string) (int, error) {
+	deleted, err := t.l2.Purge(ctx, pattern)
+	t.l1.Flush()
+	t.publish(ctx, "")
+	return deleted, err
+}
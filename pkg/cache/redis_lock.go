@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// acquireScript sets the lock when free and bumps the fencing counter. Returns the token, 0 when held.
+var acquireScript = redis.NewScript(`
+if redis.call('SET', KEYS[1], ARGV[1], 'NX', 'PX', ARGV[2]) then
+	return redis.call('INCR', KEYS[2])
+end
+return 0
+`)
+
+var extendScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+var releaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+func (r *RedisCache) Lock(ctx context.Context, key string, ttl time.Duration) (*Lock, error) {
+	return waitLock(ctx, r, key, ttl)
+}
+
+func (r *RedisCache) TryLock(ctx context.Context, key string, ttl time.Duration) (*Lock, error) {
+	return tryLock(ctx, r, key, ttl)
+}
+
+func (r *RedisCache) Fenced(ctx context.Context, key string, token int64) error {
+	return checkFence(ctx, r, key, token)
+}
+
+func (r *RedisCache) acquire(ctx context.Context, key string, value string, ttl time.Duration) (int64, bool, error) {
+	token, err := acquireScript.Run(ctx, r.client, []string{lockKey(key), fenceKey(key)}, value, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return 0, false, fmt.Errorf("error acquiring lock %s: %w", key, err)
+	}
+	return token, token > 0, nil
+}
+
+func (r *RedisCache) extend(ctx context.Context, key string, value string, ttl time.Duration) (bool, error) {
+	extended, err := extendScript.Run(ctx, r.client, []string{lockKey(key)}, value, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return false, fmt.Errorf("error extending lock %s: %w", key, err)
+	}
+	return extended == 1, nil
+}
+
+func (r *RedisCache) release(ctx context.Context, key string, value string) error {
+	if err := releaseScript.Run(ctx, r.client, []string{lockKey(key)}, value).Err(); err != nil {
+		return fmt.Errorf("error releasing lock %s: %w", key, err)
+	}
+	return nil
+}
+
+func (r *RedisCache) fence(ctx context.Context, key string) (int64, error) {
+	latest, err := r.client.Get(ctx, fenceKey(key)).Int64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return 0, fmt.Errorf("error reading the fence of lock %s: %w", key, err)
+	}
+	return latest, nil
+}
+
+func (t *TieredCache) Lock(ctx context.Context, key string, ttl time.Duration) (*Lock, error) {
+	return t.l2.Lock(ctx, key, ttl)
+}
+
+func (t *TieredCache) TryLock(ctx context.Context, key string, ttl time.Duration) (*Lock, error) {
+	return t.l2.TryLock(ctx, key, ttl)
+}
+
+func (t *TieredCache) Fenced(ctx context.Context, key string, token int64) error {
+	return t.l2.Fenced(ctx, key, token)
+}
@@ -0,0 +1,144 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultInvalidationChannel = "cache:invalidate"
+	defaultL1TTL               = 30 * time.Second
+	invalidationSeparator      = "|"
+)
+
+// TieredCache keeps a short lived in-process copy (L1) in front of Redis (L2).
+// Writes go to both tiers and publish the key on the invalidation channel so other instances drop their L1 copy.
+type TieredCache struct {
+	l1         *MemoryCache
+	l2         *RedisCache
+	l1TTL      time.Duration
+	channel    string
+	instanceId string
+	logger     Logger
+	pubsub     *redis.PubSub
+}
+
+func NewTieredCache(l1 *MemoryCache, l2 *RedisCache, l1TTL time.Duration, channel string, logger Logger) *TieredCache {
+	if l1TTL <= 0 {
+		l1TTL = defaultL1TTL
+	}
+	if channel == "" {
+		channel = defaultInvalidationChannel
+	}
+	return &TieredCache{
+		l1:         l1,
+		l2:         l2,
+		l1TTL:      l1TTL,
+		channel:    channel,
+		instanceId: uuid.NewString(),
+		logger:     logger,
+	}
+}
+
+func (t *TieredCache) Connect(ctx context.Context) error {
+	return t.l2.Connect(ctx)
+}
+
+func (t *TieredCache) Get(ctx context.Context, key string) (string, error) {
+	value, err := t.l1.Get(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	if value != "" {
+		return value, nil
+	}
+
+	value, ttl, err := t.l2.getWithTTL(ctx, key)
+	if err != nil || value == "" {
+		return value, err
+	}
+	_ = t.l1.Set(ctx, key, value, t.localTTL(ttl))
+	return value, nil
+}
+
+func (t *TieredCache) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	if err := t.l2.Set(ctx, key, value, ttl); err != nil {
+		return err
+	}
+	_ = t.l1.Set(ctx, key, value, t.localTTL(ttl))
+	t.publish(ctx, key)
+	return nil
+}
+
+func (t *TieredCache) Delete(ctx context.Context, key string) error {
+	_ = t.l1.Delete(ctx, key)
+	if err := t.l2.Delete(ctx, key); err != nil {
+		return err
+	}
+	t.publish(ctx, key)
+	return nil
+}
+
+// Subscribe listens for invalidations from other instances until ctx is done or the cache is closed.
+// L1 is flushed whenever the subscription is (re)established since messages may have been missed meanwhile.
+func (t *TieredCache) Subscribe(ctx context.Context) {
+	t.pubsub = t.l2.client.Subscribe(ctx, t.channel)
+	go func() {
+		for {
+			message, err := t.pubsub.Receive(ctx)
+			if err != nil {
+				if ctx.Err() != nil || err == redis.ErrClosed {
+					return
+				}
+				t.logger.Warnf("cache invalidation subscription: %v", err)
+				t.l1.Flush()
+				time.Sleep(time.Second)
+				continue
+			}
+			switch message := message.(type) {
+			case *redis.Subscription:
+				t.l1.Flush()
+			case *redis.Message:
+				t.invalidate(message.Payload)
+			}
+		}
+	}()
+}
+
+func (t *TieredCache) Close() error {
+	if t.pubsub != nil {
+		return t.pubsub.Close()
+	}
+	return nil
+}
+
+func (t *TieredCache) publish(ctx context.Context, key string) {
+	if err := t.l2.client.Publish(ctx, t.channel, t.instanceId+invalidationSeparator+key).Err(); err != nil {
+		t.logger.Warnf("cache invalidation publish for key %s: %v", key, err)
+	}
+}
+
+func (t *TieredCache) invalidate(payload string) {
+	origin, key, ok := strings.Cut(payload, invalidationSeparator)
+	if !ok || origin == t.instanceId {
+		return
+	}
+	// an empty key asks to drop the whole L1
+	if key == "" {
+		t.l1.Flush()
+		return
+	}
+	_ = t.l1.Delete(context.Background(), key)
+}
+
+// localTTL caps the L1 lifetime so a copy never outlives its L2 entry nor the configured L1 ttl
+func (t *TieredCache) localTTL(ttl time.Duration) time.Duration {
+	if ttl > 0 && ttl < t.l1TTL {
+		return ttl
+	}
+	return t.l1TTL
+}
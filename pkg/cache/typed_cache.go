@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// loadTimeout bounds a shared load, it no longer ends with the request that started it
+const loadTimeout = 10 * time.Second
+
+// TypedCache stores values of T in a Cache through a codec
+type TypedCache[T any] struct {
+	cache Cache
+	codec Codec
+	group singleflight.Group
+}
+
+func NewTypedCache[T any](cache Cache, codec Codec) *TypedCache[T] {
+	if codec == nil {
+		codec = JSONCodec{}
+	}
+	return &TypedCache[T]{cache: cache, codec: codec}
+}
+
+// Get returns the cached value and whether it was found
+func (c *TypedCache[T]) Get(ctx context.Context, key string) (T, bool, error) {
+	var value T
+	data, err := c.cache.Get(ctx, key)
+	if err != nil || data == "" {
+		return value, false, err
+	}
+	if err := c.codec.Unmarshal([]byte(data), &value); err != nil {
+		return value, false, err
+	}
+	return value, true, nil
+}
+
+// Set stores value, tags let InvalidateTag drop it along with the other keys of the tags
+func (c *TypedCache[T]) Set(ctx context.Context, key string, value T, ttl time.Duration, tags ...string) error {
+	data, err := c.codec.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if err := c.cache.Set(ctx, key, string(data), ttl); err != nil {
+		return err
+	}
+	return Tag(ctx, c.cache, key, ttl, tags...)
+}
+
+func (c *TypedCache[T]) Delete(ctx context.Context, key string) error {
+	return c.cache.Delete(ctx, key)
+}
+
+// GetOrLoad returns the cached value, or loads and caches it on a miss. Concurrent misses of a key share a single load.
+// The cache only speeds things up: when it fails or holds an undecodable value the loader is used, and failed loads are not cached.
+// The shared load does not inherit the cancellation of the caller that started it, each caller stops waiting when its own ctx is done.
+func (c *TypedCache[T]) GetOrLoad(ctx context.Context, key string, ttl time.Duration, loader func(ctx context.Context) (T, error), tags ...string) (T, error) {
+	if value, ok, err := c.Get(ctx, key); err == nil && ok {
+		return value, nil
+	}
+
+	loaded := c.group.DoChan(key, func() (any, error) {
+		loadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), loadTimeout)
+		defer cancel()
+		value, err := loader(loadCtx)
+		if err != nil {
+			return value, err
+		}
+		_ = c.Set(loadCtx, key, value, ttl, tags...)
+		return value, nil
+	})
+
+	var value T
+	select {
+	case <-ctx.Done():
+		return value, ctx.Err()
+	case result := <-loaded:
+		value, _ = result.Val.(T)
+		return value, result.Err
+	}
+}
@@ -58,10 +58,17 @@ type AppConfig struct {
 	Postgresql PostgresConfig   `mapstructure:"postgresql"`
 	Logger     LoggerConfig     `mapstructure:"logger"`
 	Redis      RedisConfig      `mapstructure:"redis"`
+	Cache      CacheConfig      `mapstructure:"cache"`
+	RateLimit  RateLimitConfig  `mapstructure:"rateLimit"`
 	Jwt        JWTConfig        `mapstructure:"jwt"`
 	Smtp       SMTPConfig       `mapstructure:"smtp"`
+	Mail       MailConfig       `mapstructure:"mail"`
 	Cors       CORSConfig       `mapstructure:"cors"`
 	ServiceUrl ServiceUrlConfig `mapstructure:"serviceUrl"`
+	Pagination PaginationConfig `mapstructure:"pagination"`
+	Migration  MigrationConfig  `mapstructure:"migration"`
+	Tenant     TenantConfig     `mapstructure:"tenant"`
+	Encryption EncryptionConfig `mapstructure:"encryption"`
 }
 type PostgresConfig struct {
 	Host            string `mapstructure:"host"`
@@ -75,6 +82,9 @@ type PostgresConfig struct {
 	Driver          string `mapstructure:"driver"`
 	Schema          string `mapstructure:"schema"`
 	SSLMode         bool   `mapstructure:"sslMode"`
+	// Replicas are DSNs of read replicas, ReplicaHealthCheck is the ping interval in seconds
+	Replicas           []string `mapstructure:"replicas"`
+	ReplicaHealthCheck int      `mapstructure:"replicaHealthCheck"`
 }
 
 type LoggerConfig struct {
@@ -100,6 +110,8 @@ type ServerConfig struct {
 	CtxDefaultTimeout time.Duration `mapstructure:"ctxDefaultTimeout"`
 	CSRF              bool          `mapstructure:"csrf"`
 	Debug             bool          `mapstructure:"debug"`
+	// TrustedProxies are the CIDRs allowed to set X-Forwarded-For, without any the client IP is the peer address
+	TrustedProxies []string `mapstructure:"trustedProxies"`
 }
 
 type JWTConfig struct {
@@ -111,22 +123,153 @@ type JWTConfig struct {
 	RefreshSecretKey       string `mapstructure:"refreshSecretKey"`
 	VerifyEmailSecretKey   string `mapstructure:"verifyEmailSecretKey"`
 	VerifyEmailTokenExpire int    `mapstructure:"verifyEmailTokenExpire"`
+	InviteSecretKey        string `mapstructure:"inviteSecretKey"`
+	InviteTokenExpire      int    `mapstructure:"inviteTokenExpire"`
 }
 
+// SMTPConfig mode is tls (implicit TLS, default), starttls or plain, auth is plain (default), login, cram-md5 or none.
+// Timeout bounds the dial and every command, idle connections are kept up to poolSize for idleTimeout, both in seconds.
 type SMTPConfig struct {
-	UserName string `mapstructure:"userName"`
-	Password string `mapstructure:"password"`
-	Service  string `mapstructure:"service"`
-	Port     string `mapstructure:"port"`
-	Host     string `mapstructure:"host"`
-	From     string `mapstructure:"from"`
+	UserName           string `mapstructure:"userName"`
+	Password           string `mapstructure:"password"`
+	Service            string `mapstructure:"service"`
+	Port               string `mapstructure:"port"`
+	Host               string `mapstructure:"host"`
+	From               string `mapstructure:"from"`
+	Mode               string `mapstructure:"mode"`
+	Auth               string `mapstructure:"auth"`
+	LocalName          string `mapstructure:"localName"`
+	Timeout            int    `mapstructure:"timeout"`
+	PoolSize           int    `mapstructure:"poolSize"`
+	IdleTimeout        int    `mapstructure:"idleTimeout"`
+	InsecureSkipVerify bool   `mapstructure:"insecureSkipVerify"`
 }
 
+// RedisConfig connects to a standalone server (host and port), a Sentinel setup (masterName and sentinel addresses)
+// or a Cluster (seed addresses). Zero pool sizes and timeouts keep the client defaults.
 type RedisConfig struct {
-	Host     string `mapstructure:"host"`
-	Port     int    `mapstructure:"port"`
-	UserName string `mapstructure:"userName"`
-	Password string `mapstructure:"password"`
+	Mode             string         `mapstructure:"mode"`
+	Host             string         `mapstructure:"host"`
+	Port             int            `mapstructure:"port"`
+	Addresses        []string       `mapstructure:"addresses"`
+	MasterName       string         `mapstructure:"masterName"`
+	UserName         string         `mapstructure:"userName"`
+	Password         string         `mapstructure:"password"`
+	SentinelUserName string         `mapstructure:"sentinelUserName"`
+	SentinelPassword string         `mapstructure:"sentinelPassword"`
+	DB               int            `mapstructure:"db"`
+	PoolSize         int            `mapstructure:"poolSize"`
+	MinIdleConns     int            `mapstructure:"minIdleConns"`
+	DialTimeout      time.Duration  `mapstructure:"dialTimeout"`
+	ReadTimeout      time.Duration  `mapstructure:"readTimeout"`
+	WriteTimeout     time.Duration  `mapstructure:"writeTimeout"`
+	PoolTimeout      time.Duration  `mapstructure:"poolTimeout"`
+	TLS              RedisTLSConfig `mapstructure:"tls"`
+}
+
+type RedisTLSConfig struct {
+	Enable             bool   `mapstructure:"enable"`
+	CAFile             string `mapstructure:"caFile"`
+	ServerName         string `mapstructure:"serverName"`
+	InsecureSkipVerify bool   `mapstructure:"insecureSkipVerify"`
+}
+
+// CacheConfig selects the cache implementation: redis (default), memory or tiered (memory in front of redis)
+type CacheConfig struct {
+	Driver              string `mapstructure:"driver"`
+	MaxEntries          int    `mapstructure:"maxEntries"`
+	L1TTL               int    `mapstructure:"l1Ttl"`
+	InvalidationChannel string `mapstructure:"invalidationChannel"`
+}
+
+// RateLimitConfig holds the rules of each route group, a request must pass every rule of its group
+type RateLimitConfig struct {
+	Enable       bool                       `mapstructure:"enable"`
+	ApiKeyHeader string                     `mapstructure:"apiKeyHeader"`
+	Rules        map[string][]RateLimitRule `mapstructure:"rules"`
+}
+
+// RateLimitRule allows Limit requests per Window seconds for each key (ip, user, email or apiKey).
+// For the token bucket Limit is the bucket capacity, refilled over Window seconds.
+type RateLimitRule struct {
+	Algorithm string `mapstructure:"algorithm"`
+	Key       string `mapstructure:"key"`
+	Limit     int    `mapstructure:"limit"`
+	Window    int    `mapstructure:"window"`
+}
+
+// MailConfig selects the transport delivering the mails: smtp (default), http, file or memory.
+// From is the default sender, smtp.from is used when it is empty.
+type MailConfig struct {
+	Transport string              `mapstructure:"transport"`
+	From      string              `mapstructure:"from"`
+	HTTP      MailHTTPConfig      `mapstructure:"http"`
+	File      MailFileConfig      `mapstructure:"file"`
+	Memory    MailMemoryConfig    `mapstructure:"memory"`
+	Templates MailTemplatesConfig `mapstructure:"templates"`
+	DKIM      MailDKIMConfig      `mapstructure:"dkim"`
+	Webhook   MailWebhookConfig   `mapstructure:"webhook"`
+	Outbox    MailOutboxConfig    `mapstructure:"outbox"`
+}
+
+// MailDKIMConfig signs the mails for Domain with the RSA or Ed25519 PEM key published under Selector.
+// The key is given inline or as a file, Headers lists the signed headers and defaults to all of them.
+type MailDKIMConfig struct {
+	Enable         bool     `mapstructure:"enable"`
+	Domain         string   `mapstructure:"domain"`
+	Selector       string   `mapstructure:"selector"`
+	PrivateKey     string   `mapstructure:"privateKey"`
+	PrivateKeyFile string   `mapstructure:"privateKeyFile"`
+	Headers        []string `mapstructure:"headers"`
+}
+
+// MailWebhookConfig guards the bounce webhook: callers pass Token as the token query parameter or X-Webhook-Token header.
+// Mailgun notifications are also checked against MailgunSigningKey when it is set.
+type MailWebhookConfig struct {
+	Token             string `mapstructure:"token"`
+	MailgunSigningKey string `mapstructure:"mailgunSigningKey"`
+}
+
+// MailTemplatesConfig lists dirs whose templates replace the embedded ones of the same path, the first dir wins.
+// DefaultLocale is used for users without a locale or when their locale has no template.
+type MailTemplatesConfig struct {
+	OverrideDirs  []string `mapstructure:"overrideDirs"`
+	DefaultLocale string   `mapstructure:"defaultLocale"`
+}
+
+// MailHTTPConfig posts the mails to an email API: sendgrid, mailgun or ses.
+// BaseURL overrides the provider endpoint, Timeout is in seconds.
+type MailHTTPConfig struct {
+	Provider        string `mapstructure:"provider"`
+	BaseURL         string `mapstructure:"baseUrl"`
+	ApiKey          string `mapstructure:"apiKey"`
+	Domain          string `mapstructure:"domain"`
+	Region          string `mapstructure:"region"`
+	AccessKeyId     string `mapstructure:"accessKeyId"`
+	SecretAccessKey string `mapstructure:"secretAccessKey"`
+	Timeout         int    `mapstructure:"timeout"`
+}
+
+// MailFileConfig writes every mail as an .eml file into Dir
+type MailFileConfig struct {
+	Dir string `mapstructure:"dir"`
+}
+
+// MailMemoryConfig keeps the latest MaxMessages mails in process, they are listed on the development mailbox page
+type MailMemoryConfig struct {
+	MaxMessages int `mapstructure:"maxMessages"`
+}
+
+// MailOutboxConfig tunes the outbox worker, durations are in seconds and zero values keep the defaults
+type MailOutboxConfig struct {
+	PollInterval int `mapstructure:"pollInterval"`
+	BatchSize    int `mapstructure:"batchSize"`
+	MaxAttempts  int `mapstructure:"maxAttempts"`
+	BaseBackoff  int `mapstructure:"baseBackoff"`
+	MaxBackoff   int `mapstructure:"maxBackoff"`
+	Lease        int `mapstructure:"lease"`
+	// RetentionDays is how long sent messages are kept
+	RetentionDays int `mapstructure:"retentionDays"`
 }
 
 type CORSConfig struct {
@@ -136,3 +279,24 @@ type CORSConfig struct {
 type ServiceUrlConfig struct {
 	Frontend string `mapstructure:"frontend"`
 }
+
+type PaginationConfig struct {
+	CursorSecretKey string `mapstructure:"cursorSecretKey"`
+}
+
+type MigrationConfig struct {
+	AutoMigrate bool `mapstructure:"autoMigrate"`
+	RunOnStart  bool `mapstructure:"runOnStart"`
+}
+
+type TenantConfig struct {
+	Header     string `mapstructure:"header"`
+	BaseDomain string `mapstructure:"baseDomain"`
+}
+
+// EncryptionConfig holds base64 encoded 32 byte keys, keys are looked up by id so retired ones stay readable
+type EncryptionConfig struct {
+	ActiveKey     string            `mapstructure:"activeKey"`
+	Keys          map[string]string `mapstructure:"keys"`
+	BlindIndexKey string            `mapstructure:"blindIndexKey"`
+}
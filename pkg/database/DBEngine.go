@@ -1,6 +1,8 @@
 package database
 
 import (
+	"context"
+	"database/sql"
 	"time"
 
 	"gorm.io/gorm"
@@ -25,4 +27,6 @@ type DBEngine interface {
 	Configure(...Option) DBEngine
 	Close()
 	Migrate(types ...any) error
+	Ping(ctx context.Context) error
+	Stats() sql.DBStats
 }
@@ -0,0 +1,57 @@
+package database
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	createdDateTimeUtcField = "CreatedDateTimeUtc"
+	updatedDateTimeUtcField = "UpdatedDateTimeUtc"
+	createdByField          = "CreatedBy"
+	updatedByField          = "UpdatedBy"
+)
+
+// RegisterAuditCallbacks stamps the audit tracking columns of every create and update with the UTC time and the actor of the context
+func RegisterAuditCallbacks(db *gorm.DB) error {
+	if err := db.Callback().Create().Before("gorm:create").Register("audit:create", auditCreate); err != nil {
+		return err
+	}
+	return db.Callback().Update().Before("gorm:update").Register("audit:update", auditUpdate)
+}
+
+func auditCreate(db *gorm.DB) {
+	if db.Statement.Schema == nil || db.Statement.SkipHooks {
+		return
+	}
+	now := time.Now().UTC()
+	setAuditColumn(db, createdDateTimeUtcField, &now)
+	setAuditColumn(db, updatedDateTimeUtcField, &now)
+
+	if actor, ok := ActorFromContext(db.Statement.Context); ok {
+		setAuditColumn(db, createdByField, uuid.NullUUID{UUID: actor, Valid: true})
+		setAuditColumn(db, updatedByField, uuid.NullUUID{UUID: actor, Valid: true})
+	}
+}
+
+func auditUpdate(db *gorm.DB) {
+	if db.Statement.Schema == nil || db.Statement.SkipHooks {
+		return
+	}
+	now := time.Now().UTC()
+	setAuditColumn(db, updatedDateTimeUtcField, &now)
+
+	if actor, ok := ActorFromContext(db.Statement.Context); ok {
+		setAuditColumn(db, updatedByField, uuid.NullUUID{UUID: actor, Valid: true})
+	}
+}
+
+func setAuditColumn(db *gorm.DB, name string, value any) {
+	field := db.Statement.Schema.LookUpField(name)
+	if field == nil {
+		return
+	}
+	db.Statement.SetColumn(field.DBName, value, true)
+}
@@ -0,0 +1,85 @@
+package database
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type txKey struct{}
+type withDeletedKey struct{}
+type actorKey struct{}
+type primaryKey struct{}
+type tenantKey struct{}
+type allTenantsKey struct{}
+
+// WithTx returns a copy of ctx carrying the transaction, repositories pick it up automatically
+func WithTx(ctx context.Context, tx *gorm.DB) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+// TxFromContext returns the transaction carried by ctx, if any
+func TxFromContext(ctx context.Context) (*gorm.DB, bool) {
+	tx, ok := ctx.Value(txKey{}).(*gorm.DB)
+	return tx, ok && tx != nil
+}
+
+// WithDeleted returns a copy of ctx under which repository reads also return soft-deleted rows
+func WithDeleted(ctx context.Context) context.Context {
+	return context.WithValue(ctx, withDeletedKey{}, true)
+}
+
+func includeDeleted(ctx context.Context) bool {
+	withDeleted, _ := ctx.Value(withDeletedKey{}).(bool)
+	return withDeleted
+}
+
+// WithActor returns a copy of ctx carrying the id of the user acting on the data, used to fill CreatedBy and UpdatedBy
+func WithActor(ctx context.Context, userId uuid.UUID) context.Context {
+	return context.WithValue(ctx, actorKey{}, userId)
+}
+
+// ActorFromContext returns the id of the user acting on the data, if any
+func ActorFromContext(ctx context.Context) (uuid.UUID, bool) {
+	if ctx == nil {
+		return uuid.Nil, false
+	}
+	userId, ok := ctx.Value(actorKey{}).(uuid.UUID)
+	return userId, ok && userId != uuid.Nil
+}
+
+// WithPrimary returns a copy of ctx under which reads go to the primary. Paths that update or delete what they read use it:
+// a lagging replica could hand them a row, a version or a status that no longer holds, and read-your-writes needs it too.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+func forcePrimary(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryKey{}).(bool)
+	return primary
+}
+
+// WithTenant returns a copy of ctx carrying the tenant every tenant scoped query and insert is restricted to
+func WithTenant(ctx context.Context, tenantId uuid.UUID) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantId)
+}
+
+// TenantFromContext returns the tenant resolved for the request, if any
+func TenantFromContext(ctx context.Context) (uuid.UUID, bool) {
+	if ctx == nil {
+		return uuid.Nil, false
+	}
+	tenantId, ok := ctx.Value(tenantKey{}).(uuid.UUID)
+	return tenantId, ok && tenantId != uuid.Nil
+}
+
+// WithAllTenants returns a copy of ctx under which tenant scoping is lifted, meant for cross-tenant admin jobs only
+func WithAllTenants(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allTenantsKey{}, true)
+}
+
+func allTenants(ctx context.Context) bool {
+	all, _ := ctx.Value(allTenantsKey{}).(bool)
+	return all
+}
@@ -0,0 +1,26 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrConcurrencyConflict = errors.New("entity was modified by another request")
+	ErrTenantRequired      = errors.New("tenant is required to access tenant scoped entities")
+	ErrTenantMismatch      = errors.New("entity belongs to another tenant")
+)
+
+// ConcurrencyError is returned by Update when the stored version differs from the one being saved
+type ConcurrencyError struct {
+	Entity  string
+	Version int64
+}
+
+func (e *ConcurrencyError) Error() string {
+	return fmt.Sprintf("%s: version %d of %s is stale", ErrConcurrencyConflict, e.Version, e.Entity)
+}
+
+func (e *ConcurrencyError) Is(target error) bool {
+	return target == ErrConcurrencyConflict
+}
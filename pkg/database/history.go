@@ -0,0 +1,194 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"backend/pkg/entity"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	HistoryCreate  = "CREATE"
+	HistoryUpdate  = "UPDATE"
+	HistoryDelete  = "DELETE"
+	HistoryRestore = "RESTORE"
+
+	historyTag      = "history"
+	historyRedact   = "redact"
+	historyIgnore   = "-"
+	historyRedacted = "[REDACTED]"
+)
+
+// fields that change on every save and would only add noise to the history
+var historySkippedFields = map[string]bool{
+	createdDateTimeUtcField: true,
+	updatedDateTimeUtcField: true,
+	createdByField:          true,
+	updatedByField:          true,
+	"Version":               true,
+}
+
+type FieldChange struct {
+	Field string          `json:"field"`
+	Old   json.RawMessage `json:"old,omitempty"`
+	New   json.RawMessage `json:"new,omitempty"`
+}
+
+// EntityHistory is one change of a history tracked entity, who made it and the fields it changed
+type EntityHistory struct {
+	Id         uuid.UUID     `json:"id" gorm:"primaryKey;type:uuid;"`
+	EntityType string        `json:"entityType" gorm:"type:varchar(100);not null;index:idx_entity_history_entity,priority:2;"`
+	EntityId   string        `json:"entityId" gorm:"type:varchar(64);not null;index:idx_entity_history_entity,priority:1;"`
+	Action     string        `json:"action" gorm:"type:varchar(20);not null;"`
+	Changes    []FieldChange `json:"changes" gorm:"type:text;serializer:json;"`
+	ActorId    uuid.NullUUID `json:"actorId,omitempty" gorm:"type:uuid;"`
+	OccurredAt time.Time     `json:"occurredAt" gorm:"not null;"`
+}
+
+func (EntityHistory) TableName() string {
+	return "entity_history"
+}
+
+// inHistoryTx runs fn in a transaction when T is history tracked so a change is never saved without its history
+func (r *Repository[T, Id]) inHistoryTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if !r.isHistoryTracked() {
+		return fn(ctx)
+	}
+	if _, ok := TxFromContext(ctx); ok {
+		return fn(ctx)
+	}
+	return r.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(WithTx(ctx, tx))
+	})
+}
+
+// snapshot loads the stored state of the entity before it changes, nil when T is not history tracked or the row is missing
+func (r *Repository[T, Id]) snapshot(id any, ctx context.Context) (*T, error) {
+	if !r.isHistoryTracked() {
+		return nil, nil
+	}
+	var before T
+	err := r.scoped(ctx).Where(clause.Eq{Column: clause.PrimaryColumn, Value: id}).First(&before).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &before, nil
+}
+
+// recordHistory saves the fields changed between before and after, updates changing nothing are not recorded
+func (r *Repository[T, Id]) recordHistory(ctx context.Context, action string, id any, before *T, after *T) error {
+	if !r.isHistoryTracked() {
+		return nil
+	}
+	model, err := r.schema()
+	if err != nil {
+		return err
+	}
+
+	var changes []FieldChange
+	if action == HistoryCreate || action == HistoryUpdate {
+		if changes, err = diffFields(ctx, model, before, after); err != nil {
+			return err
+		}
+		if len(changes) == 0 && action == HistoryUpdate {
+			return nil
+		}
+	}
+
+	history := &EntityHistory{
+		Id:         uuid.New(),
+		EntityType: model.Name,
+		EntityId:   fmt.Sprint(id),
+		Action:     action,
+		Changes:    changes,
+		OccurredAt: time.Now().UTC(),
+	}
+	if actor, ok := ActorFromContext(ctx); ok {
+		history.ActorId = uuid.NullUUID{UUID: actor, Valid: true}
+	}
+	return r.DB(ctx).Create(history).Error
+}
+
+func (r *Repository[T, Id]) schema() (*schema.Schema, error) {
+	stmt := &gorm.Statement{DB: r.DbContext}
+	if err := stmt.Parse(new(T)); err != nil {
+		return nil, err
+	}
+	return stmt.Schema, nil
+}
+
+func (r *Repository[T, Id]) primaryKey(value *T, ctx context.Context) (any, error) {
+	model, err := r.schema()
+	if err != nil {
+		return nil, err
+	}
+	if model.PrioritizedPrimaryField == nil {
+		return nil, fmt.Errorf("%s has no primary key", model.Name)
+	}
+	id, _ := model.PrioritizedPrimaryField.ValueOf(ctx, reflect.ValueOf(value).Elem())
+	return id, nil
+}
+
+func (r *Repository[T, Id]) isHistoryTracked() bool {
+	_, ok := any(new(T)).(entity.HistoryTracked)
+	return ok
+}
+
+func diffFields[T any](ctx context.Context, model *schema.Schema, before *T, after *T) ([]FieldChange, error) {
+	var changes []FieldChange
+	for _, field := range model.Fields {
+		tag := field.Tag.Get(historyTag)
+		if field.DBName == "" || field.PrimaryKey || tag == historyIgnore || historySkippedFields[field.Name] {
+			continue
+		}
+		oldValue, _, err := fieldJSON(ctx, field, before)
+		if err != nil {
+			return nil, err
+		}
+		newValue, zero, err := fieldJSON(ctx, field, after)
+		if err != nil {
+			return nil, err
+		}
+		if string(oldValue) == string(newValue) || (before == nil && zero) {
+			continue
+		}
+		if tag == historyRedact {
+			oldValue, newValue = redact(oldValue), redact(newValue)
+		}
+		changes = append(changes, FieldChange{Field: field.Name, Old: oldValue, New: newValue})
+	}
+	return changes, nil
+}
+
+func fieldJSON[T any](ctx context.Context, field *schema.Field, value *T) (json.RawMessage, bool, error) {
+	if value == nil {
+		return nil, true, nil
+	}
+	// read the struct field itself, ValueOf would hand back the serializer of serialized fields
+	fieldValue := field.ReflectValueOf(ctx, reflect.ValueOf(value).Elem())
+	zero := fieldValue.IsZero()
+	if zero && fieldValue.Kind() == reflect.Ptr {
+		return nil, true, nil
+	}
+	result, err := json.Marshal(fieldValue.Interface())
+	return result, zero, err
+}
+
+func redact(value json.RawMessage) json.RawMessage {
+	if value == nil {
+		return nil
+	}
+	redacted, _ := json.Marshal(historyRedacted)
+	return redacted
+}
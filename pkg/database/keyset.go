@@ -0,0 +1,162 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"backend/pkg/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// ErrUnsortable reports a sort key SeekPage can not page by, the key is unknown or its column nullable
+var ErrUnsortable = errors.New("can not page by this sort key")
+
+// SeekPage implements keyset pagination ordered by the requested sort key, with the primary key as tie breaker
+func (r *Repository[T, Id]) SeekPage(page *utils.CursorPagination, ctx context.Context, scopes ...func(*gorm.DB) *gorm.DB) (*[]T, *utils.CursorPage, error) {
+	var model T
+	stmt := &gorm.Statement{DB: r.DbContext}
+	if err := stmt.Parse(&model); err != nil {
+		return nil, nil, err
+	}
+
+	idField := stmt.Schema.PrioritizedPrimaryField
+	if idField == nil {
+		return nil, nil, fmt.Errorf("%s has no primary key", stmt.Schema.Name)
+	}
+	sortField := idField
+	if page.SortKey != "" {
+		sortField = lookUpSortField(stmt.Schema, page.SortKey)
+		if sortField == nil {
+			return nil, nil, fmt.Errorf("%w: %s has no field %s", ErrUnsortable, stmt.Schema.Name, page.SortKey)
+		}
+		// the seek condition compares the sort value, rows holding NULL would never match it and drop out of the pages
+		if !sortField.NotNull && !sortField.PrimaryKey {
+			return nil, nil, fmt.Errorf("%w: %s.%s is nullable", ErrUnsortable, stmt.Schema.Name, page.SortKey)
+		}
+	}
+
+	backward := page.After != nil && page.After.Direction == utils.CursorPrev
+	orderDesc := page.Desc != backward
+	limit := page.GetLimit()
+
+	query := r.query(ctx).Model(&model).Scopes(scopes...)
+	if page.After != nil {
+		condition, err := seekCondition(page.After, sortField, idField, orderDesc)
+		if err != nil {
+			return nil, nil, err
+		}
+		query = query.Where(condition)
+	}
+
+	orderBy := []clause.OrderByColumn{{Column: clause.Column{Name: sortField.DBName}, Desc: orderDesc}}
+	if sortField != idField {
+		orderBy = append(orderBy, clause.OrderByColumn{Column: clause.Column{Name: idField.DBName}, Desc: orderDesc})
+	}
+
+	var entities []T
+	if err := query.Clauses(clause.OrderBy{Columns: orderBy}).Limit(limit + 1).Find(&entities).Error; err != nil {
+		return nil, nil, err
+	}
+
+	hasMore := len(entities) > limit
+	if hasMore {
+		entities = entities[:limit]
+	}
+	if backward {
+		for i, j := 0, len(entities)-1; i < j; i, j = i+1, j-1 {
+			entities[i], entities[j] = entities[j], entities[i]
+		}
+	}
+
+	result := &utils.CursorPage{}
+	if len(entities) == 0 {
+		return &entities, result, nil
+	}
+
+	if (!backward && hasMore) || (backward && page.After != nil) {
+		next, err := edgeCursor(ctx, &entities[len(entities)-1], sortField, idField, page, utils.CursorNext)
+		if err != nil {
+			return nil, nil, err
+		}
+		result.Next = next
+	}
+	if (backward && hasMore) || (!backward && page.After != nil) {
+		prev, err := edgeCursor(ctx, &entities[0], sortField, idField, page, utils.CursorPrev)
+		if err != nil {
+			return nil, nil, err
+		}
+		result.Prev = prev
+	}
+
+	return &entities, result, nil
+}
+
+func lookUpSortField(s *schema.Schema, key string) *schema.Field {
+	if field := s.LookUpField(key); field != nil && field.DBName != "" {
+		return field
+	}
+	for _, field := range s.Fields {
+		if field.DBName != "" && strings.EqualFold(field.Name, key) {
+			return field
+		}
+	}
+	return nil
+}
+
+func seekCondition(cursor *utils.Cursor, sortField, idField *schema.Field, desc bool) (clause.Expression, error) {
+	op := ">"
+	if desc {
+		op = "<"
+	}
+
+	id, err := decodeCursorValue(cursor.Id, idField)
+	if err != nil {
+		return nil, err
+	}
+	if sortField == idField {
+		return clause.Expr{SQL: "? " + op + " ?", Vars: []any{clause.Column{Name: idField.DBName}, id}}, nil
+	}
+
+	value, err := decodeCursorValue(cursor.Value, sortField)
+	if err != nil {
+		return nil, err
+	}
+	sortColumn := clause.Column{Name: sortField.DBName}
+	idColumn := clause.Column{Name: idField.DBName}
+	return clause.Expr{
+		SQL:  "(? " + op + " ? OR (? = ? AND ? " + op + " ?))",
+		Vars: []any{sortColumn, value, sortColumn, value, idColumn, id},
+	}, nil
+}
+
+func decodeCursorValue(raw json.RawMessage, field *schema.Field) (any, error) {
+	value := reflect.New(field.FieldType)
+	if err := json.Unmarshal(raw, value.Interface()); err != nil {
+		return nil, utils.ErrInvalidCursor
+	}
+	return value.Elem().Interface(), nil
+}
+
+func edgeCursor(ctx context.Context, entity any, sortField, idField *schema.Field, page *utils.CursorPagination, direction utils.CursorDirection) (*utils.Cursor, error) {
+	rv := reflect.ValueOf(entity).Elem()
+	idValue, _ := idField.ValueOf(ctx, rv)
+	id, err := json.Marshal(idValue)
+	if err != nil {
+		return nil, err
+	}
+	cursor := &utils.Cursor{SortKey: page.SortKey, Desc: page.Desc, Id: id, Direction: direction}
+	if sortField != idField {
+		sortValue, _ := sortField.ValueOf(ctx, rv)
+		if cursor.Value, err = json.Marshal(sortValue); err != nil {
+			return nil, err
+		}
+	}
+	return cursor, nil
+}
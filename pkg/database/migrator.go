@@ -0,0 +1,300 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	_migrationTable   = "schema_migrations"
+	_migrationLockKey = 4_185_307_122
+)
+
+var (
+	migrationFilePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+	migrationNamePattern = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Migration
+	Applied   bool
+	AppliedAt *time.Time
+}
+
+type schemaMigration struct {
+	Version   int64     `gorm:"primaryKey;autoIncrement:false;"`
+	Name      string    `gorm:"type:varchar(256);not null;"`
+	AppliedAt time.Time `gorm:"not null;"`
+}
+
+func (schemaMigration) TableName() string {
+	return _migrationTable
+}
+
+// Migrator applies the versioned SQL files of source, recording them in the schema_migrations table
+type Migrator struct {
+	dbEngine DBEngine
+	source   fs.FS
+}
+
+func NewMigrator(dbEngine DBEngine, source fs.FS) *Migrator {
+	return &Migrator{dbEngine: dbEngine, source: source}
+}
+
+// Up applies every pending migration in version order and returns the ones applied
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	if err := m.withLock(ctx, func(tx *gorm.DB) error {
+		return tx.AutoMigrate(&schemaMigration{})
+	}); err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+	for {
+		var next *Migration
+		err := m.withLock(ctx, func(tx *gorm.DB) error {
+			// read under the lock, another instance may have applied it while this one waited
+			pending, err := m.pending(tx)
+			if err != nil || len(pending) == 0 {
+				return err
+			}
+			next = &pending[0]
+			return m.apply(tx, *next)
+		})
+		if err != nil {
+			return applied, err
+		}
+		if next == nil {
+			return applied, nil
+		}
+		applied = append(applied, *next)
+	}
+}
+
+// Down rolls back the last steps applied migrations and returns the ones rolled back
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if steps <= 0 {
+		return nil, errors.New("steps must be greater than zero")
+	}
+	migrations, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int64]Migration, len(migrations))
+	for _, migration := range migrations {
+		byVersion[migration.Version] = migration
+	}
+
+	var rolledBack []Migration
+	for range steps {
+		var last *Migration
+		err := m.withLock(ctx, func(tx *gorm.DB) error {
+			var history []schemaMigration
+			if err := tx.Order("version desc").Limit(1).Find(&history).Error; err != nil || len(history) == 0 {
+				return err
+			}
+			migration, ok := byVersion[history[0].Version]
+			if !ok {
+				return fmt.Errorf("migration %d_%s is applied but its file is missing", history[0].Version, history[0].Name)
+			}
+			last = &migration
+			return m.rollback(tx, migration)
+		})
+		if err != nil {
+			return rolledBack, err
+		}
+		if last == nil {
+			break
+		}
+		rolledBack = append(rolledBack, *last)
+	}
+	return rolledBack, nil
+}
+
+// Status lists every known migration along with whether it has been applied
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	db := m.dbEngine.GetDatabase().WithContext(ctx)
+	if err := db.AutoMigrate(&schemaMigration{}); err != nil {
+		return nil, err
+	}
+	migrations, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+	history, err := m.history(db)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]MigrationStatus, 0, len(migrations))
+	for _, migration := range migrations {
+		status := MigrationStatus{Migration: migration}
+		if row, ok := history[migration.Version]; ok {
+			appliedAt := row.AppliedAt
+			status.Applied = true
+			status.AppliedAt = &appliedAt
+		}
+		result = append(result, status)
+	}
+	return result, nil
+}
+
+// CreateMigration writes an empty up/down pair named after the current UTC time into dir
+func CreateMigration(dir string, name string) ([]string, error) {
+	name = strings.Trim(migrationNamePattern.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return nil, errors.New("migration name is required")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	version := time.Now().UTC().Format("20060102150405")
+	var files []string
+	for _, direction := range []string{"up", "down"} {
+		path := filepath.Join(dir, fmt.Sprintf("%s_%s.%s.sql", version, name, direction))
+		content := fmt.Sprintf("-- %s %s\n", name, direction)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			return nil, err
+		}
+		files = append(files, path)
+	}
+	return files, nil
+}
+
+// withLock runs fn in a transaction holding the migration lock, so replicas booting together migrate one at a time.
+// The lock is transaction scoped and taken on the connection of the transaction, the migrations never wait on a
+// second connection, which a pool of one could not hand out.
+func (m *Migrator) withLock(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	db := m.dbEngine.GetDatabase()
+	if db == nil {
+		return errors.New("database connection is nil")
+	}
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if tx.Dialector.Name() == "postgres" {
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", _migrationLockKey).Error; err != nil {
+				return fmt.Errorf("failed to acquire migration lock: %w", err)
+			}
+		}
+		return fn(tx)
+	})
+}
+
+func (m *Migrator) apply(tx *gorm.DB, migration Migration) error {
+	if strings.TrimSpace(migration.Up) != "" {
+		if err := tx.Exec(migration.Up).Error; err != nil {
+			return fmt.Errorf("migration %d_%s up: %w", migration.Version, migration.Name, err)
+		}
+	}
+	return tx.Create(&schemaMigration{
+		Version:   migration.Version,
+		Name:      migration.Name,
+		AppliedAt: time.Now().UTC(),
+	}).Error
+}
+
+func (m *Migrator) rollback(tx *gorm.DB, migration Migration) error {
+	if strings.TrimSpace(migration.Down) != "" {
+		if err := tx.Exec(migration.Down).Error; err != nil {
+			return fmt.Errorf("migration %d_%s down: %w", migration.Version, migration.Name, err)
+		}
+	}
+	return tx.Delete(&schemaMigration{}, migration.Version).Error
+}
+
+func (m *Migrator) pending(db *gorm.DB) ([]Migration, error) {
+	migrations, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+	history, err := m.history(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, migration := range migrations {
+		if _, ok := history[migration.Version]; !ok {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+func (m *Migrator) history(db *gorm.DB) (map[int64]schemaMigration, error) {
+	var rows []schemaMigration
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	history := make(map[int64]schemaMigration, len(rows))
+	for _, row := range rows {
+		history[row.Version] = row
+	}
+	return history, nil
+}
+
+func (m *Migrator) load() ([]Migration, error) {
+	entries, err := fs.ReadDir(m.source, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		content, err := fs.ReadFile(m.source, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d is used by %s and %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
@@ -1,10 +1,14 @@
 package database
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 
 	configs "backend/pkg/config"
+	"backend/pkg/entity"
 	"strconv"
 	"time"
 
@@ -16,53 +20,165 @@ type Database struct {
 	Database     *gorm.DB
 	connAttempts int
 	connTimeout  time.Duration
+	replicas     *replicaSet
 }
 
 const (
-	_defaultConnAttempts = 3
+	_defaultConnAttempts = 5
 	_defaultConnTimeout  = time.Second
+	_maxConnTimeout      = 30 * time.Second
 )
 
 type DatabaseConnectionString string
 
 func NewDatabase(appConfig *configs.AppConfig) (DBEngine, func(), error) {
-
 	config := appConfig.Postgresql
 
-	connectionString := fmt.Sprintf("host=%s port=%d user=%s dbname=%s password=%s",
-		config.Host,
-		config.Port,
-		config.UserName,
-		config.DBName,
-		config.Password,
-	)
-
 	instance := &Database{
 		connAttempts: _defaultConnAttempts,
 		connTimeout:  _defaultConnTimeout,
 	}
 
-	for instance.connAttempts > 0 {
-		database, err := gorm.Open(postgres.Open(connectionString))
+	dialector, gormConfig, err := newDialector(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	database, err := instance.connect(dialector, gormConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	instance.Database = database
+
+	if err := configurePool(database, config); err != nil {
+		instance.Close()
+		return nil, nil, err
+	}
+	if database.Dialector.Name() == "postgres" {
+		if instance.replicas, err = registerReplicas(database, config); err != nil {
+			instance.Close()
+			return nil, nil, err
+		}
+	}
+	if err := RegisterAuditCallbacks(database); err != nil {
+		instance.Close()
+		return nil, nil, err
+	}
+	return instance, instance.Close, nil
+}
 
+// newDialector picks the engine from the driver setting, Postgres unless SQLite is asked for
+func newDialector(config configs.PostgresConfig) (gorm.Dialector, *gorm.Config, error) {
+	switch config.Driver {
+	case "", entity.DRIVER_POSTGRESQL:
+		return postgres.Open(GetConnectionString(config)), &gorm.Config{DisableAutomaticPing: true}, nil
+	case entity.DRIVER_SQLITE:
+		dialector, gormConfig := newSqliteDialector(config)
+		return dialector, gormConfig, nil
+	default:
+		return nil, nil, fmt.Errorf("database driver %q is not supported", config.Driver)
+	}
+}
+
+// connect opens the database, retrying with exponential backoff while it is unreachable
+func (instance *Database) connect(dialector gorm.Dialector, gormConfig *gorm.Config) (*gorm.DB, error) {
+	var lastErr error
+	backoff := instance.connTimeout
+	for attempt := 1; attempt <= instance.connAttempts; attempt++ {
+		database, err := gorm.Open(dialector, gormConfig)
 		if err == nil {
-			instance.Database = database
+			if err = ping(database); err == nil {
+				return database, nil
+			}
+			if sqlDB, dbErr := database.DB(); dbErr == nil {
+				sqlDB.Close()
+			}
+		}
+		lastErr = err
+		if attempt == instance.connAttempts {
 			break
 		}
-		log.Printf("Postgres is trying to connect, attempts left: %d", instance.connAttempts)
-		time.Sleep(instance.connTimeout)
-		instance.connAttempts--
 
+		log.Printf("Database is trying to connect, attempts left: %d, retrying in %s", instance.connAttempts-attempt, backoff)
+		time.Sleep(backoff)
+		backoff *= 2
+		if backoff > _maxConnTimeout {
+			backoff = _maxConnTimeout
+		}
 	}
-	return instance, instance.Close, nil
+	return nil, fmt.Errorf("failed to connect to database after %d attempts: %w", instance.connAttempts, lastErr)
 }
+
+func ping(database *gorm.DB) error {
+	sqlDB, err := database.DB()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), _defaultConnTimeout*5)
+	defer cancel()
+	return sqlDB.PingContext(ctx)
+}
+
+func configurePool(database *gorm.DB, config configs.PostgresConfig) error {
+	sqlDB, err := database.DB()
+	if err != nil {
+		return err
+	}
+	if config.MaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(config.MaxIdleConns)
+	}
+	if config.MaxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(config.MaxOpenConns)
+	}
+	if config.ConnMaxLifeTime > 0 {
+		sqlDB.SetConnMaxLifetime(time.Duration(config.ConnMaxLifeTime) * time.Second)
+	}
+	if config.Driver == entity.DRIVER_SQLITE && isInMemorySqlite(config) {
+		// every connection to an in-memory database would otherwise see its own empty database
+		sqlDB.SetMaxOpenConns(1)
+		sqlDB.SetConnMaxLifetime(0)
+	}
+	return nil
+}
+
 func (instance *Database) Close() {
+	if instance.replicas != nil {
+		instance.replicas.close()
+		instance.replicas = nil
+	}
 	if instance.Database != nil {
-		sqlDB, _ := instance.Database.DB()
+		sqlDB, err := instance.Database.DB()
+		if err != nil {
+			return
+		}
 		sqlDB.Close()
 		fmt.Println("Authen DB connection closed.")
 	}
 }
+
+// Ping checks the database is reachable, used by the readiness check
+func (p *Database) Ping(ctx context.Context) error {
+	if p.Database == nil {
+		return errors.New("database connection is nil")
+	}
+	sqlDB, err := p.Database.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// Stats returns the connection pool statistics
+func (p *Database) Stats() sql.DBStats {
+	if p.Database == nil {
+		return sql.DBStats{}
+	}
+	sqlDB, err := p.Database.DB()
+	if err != nil {
+		return sql.DBStats{}
+	}
+	return sqlDB.Stats()
+}
+
 func (p *Database) Configure(opts ...Option) DBEngine {
 	for _, opt := range opts {
 		opt(p)
@@ -75,8 +191,16 @@ func (p *Database) GetDatabase() *gorm.DB {
 }
 
 func GetConnectionString(databaseSetting configs.PostgresConfig) string {
-	return fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=%s",
-		databaseSetting.Host, databaseSetting.UserName, databaseSetting.Password, databaseSetting.DBName, strconv.Itoa(databaseSetting.Port), "UTC")
+	sslMode := "disable"
+	if databaseSetting.SSLMode {
+		sslMode = "require"
+	}
+	connectionString := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=%s",
+		databaseSetting.Host, databaseSetting.UserName, databaseSetting.Password, databaseSetting.DBName, strconv.Itoa(databaseSetting.Port), sslMode, "UTC")
+	if databaseSetting.Schema != "" {
+		connectionString += fmt.Sprintf(" search_path=%s", databaseSetting.Schema)
+	}
+	return connectionString
 }
 func (p *Database) Migrate(types ...interface{}) error {
 
@@ -0,0 +1,154 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	configs "backend/pkg/config"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+const (
+	_defaultReplicaHealthCheck = 10 * time.Second
+	_replicaPingTimeout        = 2 * time.Second
+)
+
+type replica struct {
+	dsn     string
+	db      *sql.DB
+	healthy atomic.Bool
+}
+
+// replicaSet routes reads round-robin over the healthy replicas and falls back to the primary when none is
+type replicaSet struct {
+	primary  gorm.ConnPool
+	replicas []*replica
+	byPool   map[gorm.ConnPool]*replica
+	next     atomic.Uint64
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// registerReplicas installs the read/write splitting plugin when read replicas are configured
+func registerReplicas(database *gorm.DB, config configs.PostgresConfig) (*replicaSet, error) {
+	if len(config.Replicas) == 0 {
+		return nil, nil
+	}
+
+	set := &replicaSet{
+		primary: database.ConnPool,
+		byPool:  map[gorm.ConnPool]*replica{},
+		stop:    make(chan struct{}),
+	}
+	dialectors := make([]gorm.Dialector, 0, len(config.Replicas))
+	for _, dsn := range config.Replicas {
+		replicaDb, err := gorm.Open(postgres.Open(dsn), &gorm.Config{DisableAutomaticPing: true})
+		if err != nil {
+			set.close()
+			return nil, err
+		}
+		if err := configurePool(replicaDb, config); err != nil {
+			set.close()
+			return nil, err
+		}
+		sqlDB, err := replicaDb.DB()
+		if err != nil {
+			set.close()
+			return nil, err
+		}
+
+		r := &replica{dsn: dsn, db: sqlDB}
+		set.replicas = append(set.replicas, r)
+		set.byPool[sqlDB] = r
+		dialectors = append(dialectors, postgres.New(postgres.Config{Conn: sqlDB}))
+	}
+	set.checkAll()
+
+	err := database.Use(dbresolver.Register(dbresolver.Config{
+		Replicas: dialectors,
+		Policy:   set,
+	}))
+	if err != nil {
+		set.close()
+		return nil, err
+	}
+
+	interval := time.Duration(config.ReplicaHealthCheck) * time.Second
+	if interval <= 0 {
+		interval = _defaultReplicaHealthCheck
+	}
+	set.wg.Add(1)
+	go set.watch(interval)
+	return set, nil
+}
+
+// Resolve implements dbresolver.Policy
+func (s *replicaSet) Resolve(connPools []gorm.ConnPool) gorm.ConnPool {
+	start := s.next.Add(1)
+	for i := range connPools {
+		connPool := connPools[(start+uint64(i))%uint64(len(connPools))]
+		if r, ok := s.byPool[connPool]; !ok || r.healthy.Load() {
+			return connPool
+		}
+	}
+	return s.primary
+}
+
+func (s *replicaSet) watch(interval time.Duration) {
+	defer s.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.checkAll()
+		}
+	}
+}
+
+func (s *replicaSet) checkAll() {
+	for _, r := range s.replicas {
+		ctx, cancel := context.WithTimeout(context.Background(), _replicaPingTimeout)
+		err := r.db.PingContext(ctx)
+		cancel()
+
+		healthy := err == nil
+		if r.healthy.Swap(healthy) != healthy {
+			if healthy {
+				log.Printf("Postgres replica %d is in rotation", s.indexOf(r))
+			} else {
+				log.Printf("Postgres replica %d is out of rotation: %v", s.indexOf(r), err)
+			}
+		}
+	}
+}
+
+func (s *replicaSet) indexOf(target *replica) int {
+	for i, r := range s.replicas {
+		if r == target {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *replicaSet) close() {
+	select {
+	case <-s.stop:
+	default:
+		close(s.stop)
+	}
+	s.wg.Wait()
+	for _, r := range s.replicas {
+		r.db.Close()
+	}
+}
@@ -2,8 +2,15 @@ package database
 
 import (
 	"context"
+	"errors"
+	"time"
+
+	"backend/pkg/entity"
+	"backend/pkg/utils"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/plugin/dbresolver"
 )
 
 type RepositoryBase[T any, ID comparable] interface {
@@ -13,10 +20,10 @@ type RepositoryBase[T any, ID comparable] interface {
 	// Create inserts a new entity into the database
 	Create(entity *T, ctx context.Context) (*T, error)
 
-	// Update modifies an existing entity in the database
+	// Update modifies an existing entity in the database, returning a ConcurrencyError when a versioned entity is stale
 	Update(entity *T, ctx context.Context) error
 
-	// Delete removes an entity from the database by its ID
+	// Delete removes an entity from the database by its ID, soft-deletable entities are only flagged as deleted
 	Delete(id ID, ctx context.Context) error
 
 	// List retrieves all entities from the database
@@ -25,6 +32,9 @@ type RepositoryBase[T any, ID comparable] interface {
 	// SkipTake implements pagination by skipping a number of records and taking a specified amount
 	SkipTake(skip int, take int, ctx context.Context) (*[]T, error)
 
+	// SeekPage implements keyset pagination using the cursor carried by the pagination request, scopes narrow down the rows
+	SeekPage(page *utils.CursorPagination, ctx context.Context, scopes ...func(*gorm.DB) *gorm.DB) (*[]T, *utils.CursorPage, error)
+
 	// CountWhere counts the number of records matching the given parametersß
 	CountWhere(params *T, ctx context.Context) int64
 
@@ -36,8 +46,23 @@ type RepositoryBase[T any, ID comparable] interface {
 
 	// First retrieves the first entity matching the given parameters
 	First(params *T, ctx context.Context) (*T, error)
+
+	// Restore clears the deleted flag of a soft-deleted entity
+	Restore(id ID, ctx context.Context) error
 }
 
+const (
+	isDeletedColumn          = "is_deleted"
+	deletedDateTimeUtcColumn = "deleted_date_time_utc"
+	versionColumn            = "version"
+)
+
+var (
+	ErrNotSoftDeletable = errors.New("entity does not support soft delete")
+
+	notDeleted = clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: isDeletedColumn}, Value: false}
+)
+
 type Repository[T any, Id comparable] struct {
 	DbContext *gorm.DB
 }
@@ -46,9 +71,22 @@ func NewRepository[T any, Id comparable](db *gorm.DB) *Repository[T, Id] {
 	return &Repository[T, Id]{DbContext: db}
 }
 
+// DB returns the session for ctx, joining the transaction it carries if any.
+// Reads outside a transaction go to a replica unless ctx is marked with WithPrimary.
+func (r *Repository[T, Id]) DB(ctx context.Context) *gorm.DB {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx.WithContext(ctx)
+	}
+	db := r.DbContext.WithContext(ctx)
+	if forcePrimary(ctx) {
+		db = db.Clauses(dbresolver.Write)
+	}
+	return db
+}
+
 func (r *Repository[T, Id]) GetByID(id Id, ctx context.Context) (*T, error) {
 	var entity T
-	result := r.DbContext.WithContext(ctx).First(&entity, id)
+	result := r.query(ctx).First(&entity, id)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -56,49 +94,146 @@ func (r *Repository[T, Id]) GetByID(id Id, ctx context.Context) (*T, error) {
 }
 
 func (r *Repository[T, Id]) Create(entity *T, ctx context.Context) (*T, error) {
-	result := r.DbContext.WithContext(ctx).Create(entity)
-	return entity, result.Error
+	if err := r.assignTenant(entity, ctx); err != nil {
+		return entity, err
+	}
+	err := r.inHistoryTx(ctx, func(ctx context.Context) error {
+		if err := r.DB(ctx).Create(entity).Error; err != nil {
+			return err
+		}
+		id, err := r.primaryKey(entity, ctx)
+		if err != nil {
+			return err
+		}
+		return r.recordHistory(ctx, HistoryCreate, id, nil, entity)
+	})
+	return entity, err
 }
 
+// Delete flags soft-deletable entities as deleted and removes any other entity
 func (r *Repository[T, Id]) Delete(id Id, ctx context.Context) error {
-	var entity T
-	result := r.DbContext.WithContext(ctx).Delete(&entity, id)
-	return result.Error
+	return r.inHistoryTx(ctx, func(ctx context.Context) error {
+		var entity T
+		var result *gorm.DB
+		if !r.isSoftDeletable() {
+			result = r.scoped(ctx).Delete(&entity, id)
+		} else {
+			result = r.scoped(ctx).Model(&entity).
+				Where(clause.Eq{Column: clause.PrimaryColumn, Value: id}).
+				Updates(map[string]any{
+					isDeletedColumn:          true,
+					deletedDateTimeUtcColumn: time.Now().UTC(),
+				})
+		}
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return r.recordHistory(ctx, HistoryDelete, id, nil, nil)
+	})
+}
+
+// Restore clears the deleted flag of a soft-deleted entity
+func (r *Repository[T, Id]) Restore(id Id, ctx context.Context) error {
+	if !r.isSoftDeletable() {
+		return ErrNotSoftDeletable
+	}
+	return r.inHistoryTx(ctx, func(ctx context.Context) error {
+		var entity T
+		result := r.scoped(ctx).Model(&entity).
+			Where(clause.Eq{Column: clause.PrimaryColumn, Value: id}).
+			Updates(map[string]any{
+				isDeletedColumn:          false,
+				deletedDateTimeUtcColumn: nil,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return r.recordHistory(ctx, HistoryRestore, id, nil, nil)
+	})
 }
+
 func (r *Repository[T, Id]) List(ctx context.Context) (*[]T, error) {
 	var entities *[]T
-	result := r.DbContext.WithContext(ctx).Find(&entities)
+	result := r.query(ctx).Find(&entities)
 	return entities, result.Error
 }
 
+// Update saves the entity, versioned entities are only saved when the stored version still matches theirs
 func (r *Repository[T, ID]) Update(entity *T, ctx context.Context) error {
-	result := r.DbContext.WithContext(ctx).Save(entity)
-	return result.Error
+	if err := r.assignTenant(entity, ctx); err != nil {
+		return err
+	}
+	if !r.isHistoryTracked() {
+		return r.update(entity, ctx)
+	}
+	return r.inHistoryTx(ctx, func(ctx context.Context) error {
+		id, err := r.primaryKey(entity, ctx)
+		if err != nil {
+			return err
+		}
+		before, err := r.snapshot(id, ctx)
+		if err != nil {
+			return err
+		}
+		if err := r.update(entity, ctx); err != nil {
+			return err
+		}
+		return r.recordHistory(ctx, HistoryUpdate, id, before, entity)
+	})
+}
+
+func (r *Repository[T, ID]) update(entity *T, ctx context.Context) error {
+	versioned, ok := asVersioned(entity)
+	if !ok {
+		if !r.isTenantScoped() {
+			return r.DB(ctx).Save(entity).Error
+		}
+		return r.scoped(ctx).Model(entity).Select("*").Updates(entity).Error
+	}
+
+	version := versioned.GetVersion()
+	versioned.SetVersion(version + 1)
+	result := r.scoped(ctx).Model(entity).
+		Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: versionColumn}, Value: version}).
+		Select("*").
+		Updates(entity)
+	if result.Error != nil {
+		versioned.SetVersion(version)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		versioned.SetVersion(version)
+		return &ConcurrencyError{Entity: result.Statement.Table, Version: version}
+	}
+	return nil
 }
 
 func (r *Repository[T, ID]) SkipTake(skip int, take int, ctx context.Context) (*[]T, error) {
 	var entities *[]T
-	result := r.DbContext.WithContext(ctx).Offset(skip).Limit(take).Find(&entities)
+	result := r.query(ctx).Offset(skip).Limit(take).Find(&entities)
 	return entities, result.Error
 }
 
 func (r *Repository[T, ID]) Count(ctx context.Context) int64 {
 	var entity T
 	var count int64
-	r.DbContext.WithContext(ctx).Model(&entity).Count(&count)
+	r.query(ctx).Model(&entity).Count(&count)
 	return count
 }
 
 func (r *Repository[T, ID]) CountWhere(params *T, ctx context.Context) int64 {
 	var entity T
 	var count int64
-	r.DbContext.WithContext(ctx).Model(&entity).Where(&params).Count(&count)
+	r.query(ctx).Model(&entity).Where(&params).Count(&count)
 	return count
 }
 
 func (r *Repository[T, Id]) Where(params *T, ctx context.Context) (*[]T, error) {
 	var entities []T
-	err := r.DbContext.WithContext(ctx).Where(&params).Find(&entities).Error
+	err := r.query(ctx).Where(&params).Find(&entities).Error
 	if err != nil {
 		return nil, err
 	}
@@ -107,7 +242,7 @@ func (r *Repository[T, Id]) Where(params *T, ctx context.Context) (*[]T, error)
 
 func (r *Repository[T, Id]) WhereNotDeleted(params *T, ctx context.Context) (*[]T, error) {
 	var entities []T
-	err := r.DbContext.WithContext(ctx).Where(params).Where("is_deleted = ?", false).Find(&entities).Error
+	err := r.scoped(ctx).Where(params).Where(notDeleted).Find(&entities).Error
 	if err != nil {
 		return nil, err
 	}
@@ -116,9 +251,28 @@ func (r *Repository[T, Id]) WhereNotDeleted(params *T, ctx context.Context) (*[]
 
 func (r *Repository[T, Id]) First(params *T, ctx context.Context) (*T, error) {
 	var entity T
-	err := r.DbContext.WithContext(ctx).Where(&params).FirstOrInit(&entity).Error
+	err := r.query(ctx).Where(&params).First(&entity).Error
 	if err != nil {
 		return nil, err
 	}
 	return &entity, nil
 }
+
+// query returns the session used by reads, hiding soft-deleted rows unless ctx asks for them
+func (r *Repository[T, Id]) query(ctx context.Context) *gorm.DB {
+	db := r.scoped(ctx)
+	if r.isSoftDeletable() && !includeDeleted(ctx) {
+		db = db.Where(notDeleted)
+	}
+	return db
+}
+
+func asVersioned(value any) (entity.VersionedEntity, bool) {
+	versioned, ok := value.(entity.VersionedEntity)
+	return versioned, ok
+}
+
+func (r *Repository[T, Id]) isSoftDeletable() bool {
+	_, ok := any(new(T)).(entity.SoftDeletable)
+	return ok
+}
@@ -0,0 +1,50 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	configs "backend/pkg/config"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+const _sqliteMemory = ":memory:"
+
+// sqliteNamer flattens schema qualified table names since SQLite has no schemas, authentication.users becomes authentication_users
+type sqliteNamer struct {
+	schema.NamingStrategy
+}
+
+func (sqliteNamer) SchemaTableName(schemaName string, table string) string {
+	return schemaName + "_" + table
+}
+
+func newSqliteDialector(config configs.PostgresConfig) (gorm.Dialector, *gorm.Config) {
+	return sqlite.Open(GetSqliteConnectionString(config)), &gorm.Config{
+		DisableAutomaticPing: true,
+		NamingStrategy:       sqliteNamer{},
+	}
+}
+
+// GetSqliteConnectionString uses dbName as the database file, an empty name or ":memory:" keeps the database in memory
+func GetSqliteConnectionString(databaseSetting configs.PostgresConfig) string {
+	path := databaseSetting.DBName
+	if path == "" || path == _sqliteMemory {
+		path = "file::memory:?cache=shared"
+	} else if !strings.HasPrefix(path, "file:") {
+		path = "file:" + path
+	}
+
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+	return fmt.Sprintf("%s%s_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)", path, separator)
+}
+
+func isInMemorySqlite(databaseSetting configs.PostgresConfig) bool {
+	return databaseSetting.DBName == "" || databaseSetting.DBName == _sqliteMemory || strings.Contains(databaseSetting.DBName, "mode=memory")
+}
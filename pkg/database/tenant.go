@@ -0,0 +1,52 @@
+package database
+
+import (
+	"context"
+
+	"backend/pkg/entity"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const tenantIdColumn = "tenant_id"
+
+// scoped returns the session for ctx restricted to the tenant it carries when T is tenant scoped
+func (r *Repository[T, Id]) scoped(ctx context.Context) *gorm.DB {
+	db := r.DB(ctx)
+	if !r.isTenantScoped() || allTenants(ctx) {
+		return db
+	}
+	tenantId, ok := TenantFromContext(ctx)
+	if !ok {
+		db.AddError(ErrTenantRequired)
+		return db
+	}
+	return db.Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: tenantIdColumn}, Value: tenantId})
+}
+
+// assignTenant stamps the tenant of ctx on a tenant scoped entity, refusing entities of another tenant
+func (r *Repository[T, Id]) assignTenant(value *T, ctx context.Context) error {
+	scoped, ok := any(value).(entity.TenantScoped)
+	if !ok || allTenants(ctx) {
+		return nil
+	}
+	tenantId, ok := TenantFromContext(ctx)
+	if !ok {
+		return ErrTenantRequired
+	}
+	switch scoped.GetTenantId() {
+	case uuid.Nil:
+		scoped.SetTenantId(tenantId)
+	case tenantId:
+	default:
+		return ErrTenantMismatch
+	}
+	return nil
+}
+
+func (r *Repository[T, Id]) isTenantScoped() bool {
+	_, ok := any(new(T)).(entity.TenantScoped)
+	return ok
+}
@@ -0,0 +1,100 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+const (
+	_defaultTxRetries = 3
+	_defaultTxBackoff = 50 * time.Millisecond
+
+	pgSerializationFailure = "40001"
+	pgDeadlockDetected     = "40P01"
+)
+
+type TxOption func(*txOptions)
+
+type txOptions struct {
+	maxRetries int
+	backoff    time.Duration
+	sqlOptions *sql.TxOptions
+}
+
+// TxRetries sets how many times a transaction is retried after a serialization failure
+func TxRetries(retries int) TxOption {
+	return func(o *txOptions) {
+		o.maxRetries = retries
+	}
+}
+
+// TxIsolation sets the isolation level of the outermost transaction
+func TxIsolation(level sql.IsolationLevel) TxOption {
+	return func(o *txOptions) {
+		o.sqlOptions = &sql.TxOptions{Isolation: level}
+	}
+}
+
+type UnitOfWork interface {
+	// Do runs fn in a transaction carried by the context passed to fn.
+	// When ctx already carries a transaction fn runs inside a savepoint of it.
+	Do(ctx context.Context, fn func(ctx context.Context) error, opts ...TxOption) error
+}
+
+type unitOfWork struct {
+	dbEngine DBEngine
+}
+
+func NewUnitOfWork(dbEngine DBEngine) UnitOfWork {
+	return &unitOfWork{dbEngine: dbEngine}
+}
+
+func (u *unitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error, opts ...TxOption) error {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx.WithContext(ctx).Transaction(func(savepoint *gorm.DB) error {
+			return fn(WithTx(ctx, savepoint))
+		})
+	}
+
+	options := &txOptions{maxRetries: _defaultTxRetries, backoff: _defaultTxBackoff}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	db := u.dbEngine.GetDatabase()
+	for attempt := 0; ; attempt++ {
+		err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return fn(WithTx(ctx, tx))
+		}, txSQLOptions(options)...)
+
+		if err == nil || !isRetryableTxError(err) || attempt >= options.maxRetries {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(options.backoff << attempt):
+		}
+	}
+}
+
+func txSQLOptions(options *txOptions) []*sql.TxOptions {
+	if options.sqlOptions == nil {
+		return nil
+	}
+	return []*sql.TxOptions{options.sqlOptions}
+}
+
+func isRetryableTxError(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == pgSerializationFailure || pgErr.Code == pgDeadlockDetected
+	}
+	return false
+}
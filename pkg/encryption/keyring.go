@@ -0,0 +1,225 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	configs "backend/pkg/config"
+)
+
+const (
+	_keySize        = 32
+	_envelopePrefix = "enc"
+	_envelopeFormat = "v1"
+)
+
+var (
+	ErrNotConfigured     = errors.New("encryption keys are not configured")
+	ErrUnknownKey        = errors.New("encryption key is unknown")
+	ErrMalformedEnvelope = errors.New("encrypted value is malformed")
+)
+
+var defaultKeyring atomic.Pointer[Keyring]
+
+// Keyring holds the versioned key encryption keys, values are sealed with the active one and opened with whichever sealed them
+type Keyring struct {
+	activeKey     string
+	keys          map[string]cipher.AEAD
+	blindIndexKey []byte
+}
+
+func NewKeyring(config configs.EncryptionConfig) (*Keyring, error) {
+	// name the missing setting, a bare "not configured" leaves the operator guessing at boot
+	switch {
+	case len(config.Keys) == 0:
+		return nil, fmt.Errorf("%w: encryption.keys is not set", ErrNotConfigured)
+	case config.ActiveKey == "":
+		return nil, fmt.Errorf("%w: encryption.activeKey is not set", ErrNotConfigured)
+	case config.BlindIndexKey == "":
+		return nil, fmt.Errorf("%w: encryption.blindIndexKey is not set", ErrNotConfigured)
+	}
+
+	keyring := &Keyring{
+		activeKey: strings.ToLower(config.ActiveKey),
+		keys:      make(map[string]cipher.AEAD, len(config.Keys)),
+	}
+	for id, encoded := range config.Keys {
+		id = strings.ToLower(id)
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("encryption key id %q is invalid", id)
+		}
+		if encoded == "" {
+			return nil, fmt.Errorf("%w: encryption.keys.%s is not set", ErrNotConfigured, id)
+		}
+		key, err := decodeKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("encryption key %s: %w", id, err)
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, err
+		}
+		keyring.keys[id] = aead
+	}
+	if _, ok := keyring.keys[keyring.activeKey]; !ok {
+		return nil, fmt.Errorf("active encryption key %q is not configured", config.ActiveKey)
+	}
+
+	blindIndexKey, err := decodeKey(config.BlindIndexKey)
+	if err != nil {
+		return nil, fmt.Errorf("blind index key: %w", err)
+	}
+	keyring.blindIndexKey = blindIndexKey
+	return keyring, nil
+}
+
+// Register builds the keyring from config and installs it for the encrypted serializer and blind indexes
+func Register(appConfig *configs.AppConfig) error {
+	keyring, err := NewKeyring(appConfig.Encryption)
+	if err != nil {
+		return err
+	}
+	Use(keyring)
+	return nil
+}
+
+// Use installs keyring as the one used by the encrypted serializer and blind indexes
+func Use(keyring *Keyring) {
+	defaultKeyring.Store(keyring)
+}
+
+func current() (*Keyring, error) {
+	keyring := defaultKeyring.Load()
+	if keyring == nil {
+		return nil, ErrNotConfigured
+	}
+	return keyring, nil
+}
+
+// Encrypt seals plaintext under a fresh data key which is itself sealed with the active key.
+// aad binds the value to where it is stored so it can not be copied to another column.
+func (k *Keyring) Encrypt(plaintext []byte, aad []byte) (string, error) {
+	dataKey := make([]byte, _keySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	wrappedKey, err := seal(k.keys[k.activeKey], dataKey, []byte(k.activeKey))
+	if err != nil {
+		return "", err
+	}
+	ciphertext, err := seal(dataAEAD, plaintext, aad)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join([]string{
+		_envelopePrefix,
+		_envelopeFormat,
+		k.activeKey,
+		base64.RawURLEncoding.EncodeToString(wrappedKey),
+		base64.RawURLEncoding.EncodeToString(ciphertext),
+	}, ":"), nil
+}
+
+// Decrypt opens a value sealed by Encrypt with any key of the keyring
+func (k *Keyring) Decrypt(envelope string, aad []byte) ([]byte, error) {
+	parts := strings.Split(envelope, ":")
+	if len(parts) != 5 || parts[0] != _envelopePrefix || parts[1] != _envelopeFormat {
+		return nil, ErrMalformedEnvelope
+	}
+	keyAEAD, ok := k.keys[parts[2]]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	wrappedKey, err := base64.RawURLEncoding.DecodeString(parts[3])
+	if err != nil {
+		return nil, ErrMalformedEnvelope
+	}
+	ciphertext, err := base64.RawURLEncoding.DecodeString(parts[4])
+	if err != nil {
+		return nil, ErrMalformedEnvelope
+	}
+
+	dataKey, err := open(keyAEAD, wrappedKey, []byte(parts[2]))
+	if err != nil {
+		return nil, err
+	}
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return open(dataAEAD, ciphertext, aad)
+}
+
+// BlindIndex returns a keyed hash of the normalized value, equal values give equal indexes without revealing them
+func (k *Keyring) BlindIndex(value string) string {
+	mac := hmac.New(sha256.New, k.blindIndexKey)
+	mac.Write([]byte(Normalize(value)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// BlindIndex hashes value with the installed keyring
+func BlindIndex(value string) (string, error) {
+	keyring, err := current()
+	if err != nil {
+		return "", err
+	}
+	return keyring.BlindIndex(value), nil
+}
+
+// Normalize is applied to values before they are blind indexed
+func Normalize(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+func isEnvelope(value string) bool {
+	return strings.HasPrefix(value, _envelopePrefix+":")
+}
+
+func decodeKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.New("key must be base64 encoded")
+	}
+	if len(key) != _keySize {
+		return nil, fmt.Errorf("key must be %d bytes", _keySize)
+	}
+	return key, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func seal(aead cipher.AEAD, plaintext []byte, aad []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+func open(aead cipher.AEAD, sealed []byte, aad []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrMalformedEnvelope
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, aad)
+}
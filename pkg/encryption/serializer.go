@@ -0,0 +1,70 @@
+package encryption
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm/schema"
+)
+
+func init() {
+	schema.RegisterSerializer("encrypted", EncryptedSerializer{})
+}
+
+// EncryptedSerializer stores the JSON of a field sealed by the installed keyring, used as `gorm:"serializer:encrypted"`.
+// Values written before the column was encrypted are read as plain JSON until they are re-encrypted.
+type EncryptedSerializer struct{}
+
+func (EncryptedSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue any) error {
+	fieldValue := reflect.New(field.FieldType)
+	if dbValue != nil {
+		var stored string
+		switch v := dbValue.(type) {
+		case string:
+			stored = v
+		case []byte:
+			stored = string(v)
+		default:
+			return fmt.Errorf("failed to decrypt %s: unsupported value %#v", field.Name, dbValue)
+		}
+
+		plaintext := []byte(stored)
+		if isEnvelope(stored) {
+			keyring, err := current()
+			if err != nil {
+				return err
+			}
+			if plaintext, err = keyring.Decrypt(stored, associatedData(field)); err != nil {
+				return fmt.Errorf("failed to decrypt %s: %w", field.Name, err)
+			}
+		}
+		if len(plaintext) > 0 {
+			if err := json.Unmarshal(plaintext, fieldValue.Interface()); err != nil {
+				return fmt.Errorf("failed to decode %s: %w", field.Name, err)
+			}
+		}
+	}
+	field.ReflectValueOf(ctx, dst).Set(fieldValue.Elem())
+	return nil
+}
+
+func (EncryptedSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue any) (any, error) {
+	if rv := reflect.ValueOf(fieldValue); !rv.IsValid() || (rv.Kind() == reflect.Ptr && rv.IsNil()) {
+		return nil, nil
+	}
+	keyring, err := current()
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := json.Marshal(fieldValue)
+	if err != nil {
+		return nil, err
+	}
+	return keyring.Encrypt(plaintext, associatedData(field))
+}
+
+func associatedData(field *schema.Field) []byte {
+	return []byte(field.Schema.Name + "." + field.DBName)
+}
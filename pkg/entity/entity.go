@@ -5,6 +5,7 @@ import (
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
 )
 
 type DbContext interface {
@@ -20,8 +21,22 @@ const (
 )
 const (
 	DRIVER_POSTGRESQL = "postgresql"
+	DRIVER_SQLITE     = "sqlite"
 )
 
+// SchemaNamer is implemented by the naming strategy of engines without schema support
+type SchemaNamer interface {
+	SchemaTableName(schema string, table string) string
+}
+
+// TableName qualifies table with its schema, or lets an engine without schemas map it
+func TableName(namer schema.Namer, schemaName string, table string) string {
+	if schemaNamer, ok := namer.(SchemaNamer); ok {
+		return schemaNamer.SchemaTableName(schemaName, table)
+	}
+	return schemaName + "." + table
+}
+
 type DateTimeTracking struct {
 	CreatedDateTimeUtc *time.Time `json:"createdDateTimeUtc,omitempty" gorm:"not null;"`
 	UpdatedDateTimeUtc *time.Time `json:"updatedDateTimeUtc,omitempty" gorm:"not null;"`
@@ -37,9 +52,69 @@ type SoftDelete struct {
 	IsDeleted          bool       `json:"isDeleted" gorm:"default:false;not null;"`
 	DeletedDateTimeUtc *time.Time `json:"deletedDateTimeUtc,omitempty" gorm:"null;"`
 }
+
+// SoftDeletable is implemented by entities embedding SoftDelete, repositories flag them instead of removing rows
+type SoftDeletable interface {
+	MarkDeleted(at time.Time)
+	Restore()
+}
+
+func (s *SoftDelete) MarkDeleted(at time.Time) {
+	s.IsDeleted = true
+	s.DeletedDateTimeUtc = &at
+}
+
+func (s *SoftDelete) Restore() {
+	s.IsDeleted = false
+	s.DeletedDateTimeUtc = nil
+}
+
+// Versioned enables optimistic concurrency, repositories only update the row when its version is unchanged
+type Versioned struct {
+	Version int64 `json:"version" gorm:"not null;default:1;"`
+}
+
+type VersionedEntity interface {
+	GetVersion() int64
+	SetVersion(version int64)
+}
+
+func (v *Versioned) GetVersion() int64 {
+	return v.Version
+}
+
+func (v *Versioned) SetVersion(version int64) {
+	v.Version = version
+}
+
+// Multitenant marks an entity as owned by a tenant, repositories scope every query and insert to the tenant of the context
 type Multitenant struct {
-	Id uuid.UUID `json:"tenantId" gorm:"type:uuid;null;"`
+	TenantId uuid.UUID `json:"tenantId" gorm:"type:uuid;not null;index;"`
+}
+
+type TenantScoped interface {
+	GetTenantId() uuid.UUID
+	SetTenantId(tenantId uuid.UUID)
+}
+
+func (m *Multitenant) GetTenantId() uuid.UUID {
+	return m.TenantId
+}
+
+func (m *Multitenant) SetTenantId(tenantId uuid.UUID) {
+	m.TenantId = tenantId
+}
+
+// WithHistory makes repositories record every change of the entity in the entity history.
+// Fields tagged `history:"redact"` are recorded as changed without their values, `history:"-"` are left out.
+type WithHistory struct{}
+
+type HistoryTracked interface {
+	TrackHistory()
 }
+
+func (WithHistory) TrackHistory() {}
+
 type BaseAuditTrackingEntity struct {
 	Entity
 	DateTimeTracking `gorm:"embedded"`
@@ -50,20 +125,17 @@ type BaseEntitySoftDelete struct {
 	SoftDelete              `gorm:"embedded"`
 }
 
+// NewSQLModel assigns a new id, audit columns are stamped by the database callbacks on save
 func NewSQLModel() BaseAuditTrackingEntity {
-	now := time.Now().UTC()
-	id := uuid.New()
 	return BaseAuditTrackingEntity{
 		Entity: Entity{
-			Id: id,
-		},
-		DateTimeTracking: DateTimeTracking{
-			CreatedDateTimeUtc: &now,
-			UpdatedDateTimeUtc: &now,
-		},
-		AuditTracking: AuditTracking{
-			CreatedBy: uuid.NullUUID{Valid: false},
-			UpdatedBy: uuid.NullUUID{Valid: false},
+			Id: uuid.New(),
 		},
 	}
 }
+
+func NewSoftDeleteSQLModel() BaseEntitySoftDelete {
+	return BaseEntitySoftDelete{
+		BaseAuditTrackingEntity: NewSQLModel(),
+	}
+}
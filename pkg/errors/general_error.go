@@ -6,6 +6,9 @@ const (
 	Success       GeneralErrorValue = 0 + iota
 	DatabaseError GeneralErrorValue = 501
 	DataInvalid   GeneralErrorValue = 502
+	DataConflict  GeneralErrorValue = 503
+	RateLimited   GeneralErrorValue = 504
+	NotFound      GeneralErrorValue = 505
 )
 
 type AppError interface {
@@ -36,4 +39,7 @@ var GeneralMessage = map[GeneralErrorValue]string{
 	Success:       "Successfully!",
 	DatabaseError: "DB Error",
 	DataInvalid:   "Data is invalid",
+	DataConflict:  "Data was modified by another request",
+	RateLimited:   "Too many requests, try again later",
+	NotFound:      "Data is not exists",
 }
@@ -0,0 +1,34 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	HeaderETag    = "ETag"
+	HeaderIfMatch = "If-Match"
+)
+
+// SetETag exposes the entity version as a strong ETag
+func SetETag(ctx echo.Context, version int64) {
+	ctx.Response().Header().Set(HeaderETag, fmt.Sprintf(`"%d"`, version))
+}
+
+// IfMatchVersion reads the entity version expected by the client, nil when the header is absent or "*"
+func IfMatchVersion(ctx echo.Context) (*int64, error) {
+	value := strings.TrimSpace(ctx.Request().Header.Get(HeaderIfMatch))
+	if value == "" || value == "*" {
+		return nil, nil
+	}
+	value = strings.Trim(strings.TrimPrefix(value, "W/"), `"`)
+	version, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "If-Match header must be an entity tag returned by the API")
+	}
+	return &version, nil
+}
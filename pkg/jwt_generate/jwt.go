@@ -23,14 +23,20 @@ type jwtGenerate struct {
 	ctx                   context.Context
 	verifyEmailSecretKey  string
 	verifyEmailExpiresAt  time.Duration
+	inviteSecretKey       string
+	inviteExpiresAt       time.Duration
 }
 type TokenPayload struct {
-	UserId uuid.UUID
-	Email  string
+	UserId   uuid.UUID
+	Email    string
+	TenantId uuid.UUID
+	// InvitationId is only carried by invitation tokens
+	InvitationId uuid.UUID
 }
 type JwtGenerate interface {
 	GenerateToken(user *TokenPayload) (string, error)
 	GenerateVerifyEmailToken(user *TokenPayload) (string, error)
+	GenerateInviteToken(user *TokenPayload) (string, error)
 	GenerateRefreshToken(user *TokenPayload) (string, error)
 	VerifyToken(refreshToken string, secretKey string) (*TokenPayload, error)
 }
@@ -47,6 +53,8 @@ func NewJwtGenerate(ctx context.Context, config *configs.AppConfig, redisCache c
 		ctx:                   ctx,
 		verifyEmailSecretKey:  config.Jwt.VerifyEmailSecretKey,
 		verifyEmailExpiresAt:  time.Duration(config.Jwt.VerifyEmailTokenExpire) * time.Hour,
+		inviteSecretKey:       config.Jwt.InviteSecretKey,
+		inviteExpiresAt:       time.Duration(config.Jwt.InviteTokenExpire) * time.Hour,
 	}
 }
 
@@ -59,6 +67,12 @@ func (j *jwtGenerate) generateTokenWithClaims(user *TokenPayload, secretKey stri
 		"iss":   j.issuer,
 		"aud":   j.audience,
 	}
+	if user.TenantId != uuid.Nil {
+		claims["tid"] = user.TenantId
+	}
+	if user.InvitationId != uuid.Nil {
+		claims["inv"] = user.InvitationId
+	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(secretKey))
 }
@@ -71,16 +85,24 @@ func (j *jwtGenerate) GenerateVerifyEmailToken(user *TokenPayload) (string, erro
 	return j.generateTokenWithClaims(user, j.verifyEmailSecretKey, j.verifyEmailExpiresAt)
 }
 
+func (j *jwtGenerate) GenerateInviteToken(user *TokenPayload) (string, error) {
+	return j.generateTokenWithClaims(user, j.inviteSecretKey, j.inviteExpiresAt)
+}
+
 func (j *jwtGenerate) GenerateRefreshToken(user *TokenPayload) (string, error) {
 	refreshToken, err := j.generateTokenWithClaims(user, j.refreshTokenSecretKey, j.refreshTokenExpiresAt)
 	if err != nil {
 		return "", err
 	}
 
-	err = j.redisCache.Set(j.ctx, cache.RefreshTokenKey(user.UserId), refreshToken, int(j.refreshTokenExpiresAt))
+	key := cache.RefreshTokenKey.Build(user.UserId)
+	err = j.redisCache.Set(j.ctx, key, refreshToken, j.refreshTokenExpiresAt)
 	if err != nil {
 		return "", err
 	}
+	if err := cache.Tag(j.ctx, j.redisCache, key, j.refreshTokenExpiresAt, cache.UserTag(user.UserId)); err != nil {
+		return "", err
+	}
 
 	return refreshToken, nil
 }
@@ -113,5 +135,19 @@ func (j *jwtGenerate) VerifyToken(refreshToken string, secretKey string) (*Token
 		UserId: uuid.MustParse(claims["id"].(string)),
 		Email:  claims["email"].(string),
 	}
+	if tid, ok := claims["tid"].(string); ok {
+		tenantId, err := uuid.Parse(tid)
+		if err != nil {
+			return nil, errors.New("invalid tenant")
+		}
+		result.TenantId = tenantId
+	}
+	if inv, ok := claims["inv"].(string); ok {
+		invitationId, err := uuid.Parse(inv)
+		if err != nil {
+			return nil, errors.New("invalid invitation")
+		}
+		result.InvitationId = invitationId
+	}
 	return result, nil
 }
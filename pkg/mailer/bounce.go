@@ -0,0 +1,197 @@
+package mailer
+
+import (
+	"crypto/hmac"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	configs "backend/pkg/config"
+)
+
+const maxWebhookAge = 5 * time.Minute
+
+var ErrWebhookSignature = errors.New("webhook signature is invalid")
+
+// BounceNotification is what a provider webhook reported, deliveries and soft bounces are left out
+type BounceNotification struct {
+	Events []BounceEvent
+	// SubscribeURL is set when SNS asks to confirm the subscription of the webhook
+	SubscribeURL string
+}
+
+// BounceEvent is an address to suppress, Reason is SuppressionBounce or SuppressionComplaint
+type BounceEvent struct {
+	Address string
+	Reason  string
+	Detail  string
+}
+
+// ParseBounceNotification reads the webhook body of provider: sendgrid (event webhook), mailgun or ses (through SNS)
+func ParseBounceNotification(provider string, body []byte, config configs.MailWebhookConfig, now time.Time) (*BounceNotification, error) {
+	switch strings.ToLower(provider) {
+	case HTTP_PROVIDER_SENDGRID:
+		return parseSendGridEvents(body)
+	case HTTP_PROVIDER_MAILGUN:
+		return parseMailgunEvent(body, config.MailgunSigningKey, now)
+	case HTTP_PROVIDER_SES:
+		return parseSESNotification(body)
+	}
+	return nil, fmt.Errorf("unknown mail provider %q", provider)
+}
+
+type sendGridEvent struct {
+	Email  string `json:"email"`
+	Event  string `json:"event"`
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+}
+
+func parseSendGridEvents(body []byte) (*BounceNotification, error) {
+	var events []sendGridEvent
+	if err := json.Unmarshal(body, &events); err != nil {
+		return nil, err
+	}
+	notification := &BounceNotification{}
+	for _, event := range events {
+		switch {
+		// a blocked bounce is a temporary refusal, the address itself is fine
+		case event.Event == "bounce" && event.Type != "blocked":
+			notification.add(event.Email, SuppressionBounce, event.Reason)
+		case event.Event == "spamreport":
+			notification.add(event.Email, SuppressionComplaint, "")
+		}
+	}
+	return notification, nil
+}
+
+type mailgunWebhook struct {
+	Signature struct {
+		Timestamp string `json:"timestamp"`
+		Token     string `json:"token"`
+		Signature string `json:"signature"`
+	} `json:"signature"`
+	EventData struct {
+		Event          string `json:"event"`
+		Severity       string `json:"severity"`
+		Recipient      string `json:"recipient"`
+		DeliveryStatus struct {
+			Message     string `json:"message"`
+			Description string `json:"description"`
+		} `json:"delivery-status"`
+	} `json:"event-data"`
+}
+
+func parseMailgunEvent(body []byte, signingKey string, now time.Time) (*BounceNotification, error) {
+	var webhook mailgunWebhook
+	if err := json.Unmarshal(body, &webhook); err != nil {
+		return nil, err
+	}
+	if signingKey != "" {
+		signature := webhook.Signature
+		if !validMailgunSignature(signingKey, signature.Timestamp, signature.Token, signature.Signature, now) {
+			return nil, ErrWebhookSignature
+		}
+	}
+
+	notification := &BounceNotification{}
+	event := webhook.EventData
+	switch {
+	case event.Event == "failed" && event.Severity == "permanent":
+		detail := event.DeliveryStatus.Description
+		if detail == "" {
+			detail = event.DeliveryStatus.Message
+		}
+		notification.add(event.Recipient, SuppressionBounce, detail)
+	case event.Event == "complained":
+		notification.add(event.Recipient, SuppressionComplaint, "")
+	}
+	return notification, nil
+}
+
+// validMailgunSignature checks the HMAC of timestamp and token, stale timestamps are refused against replays
+func validMailgunSignature(signingKey, timestamp, token, signature string, now time.Time) bool {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if age := now.Sub(time.Unix(seconds, 0)); age > maxWebhookAge || age < -maxWebhookAge {
+		return false
+	}
+	expected := hmacSHA256([]byte(signingKey), timestamp+token)
+	given, err := hex.DecodeString(signature)
+	return err == nil && hmac.Equal(expected, given)
+}
+
+type snsMessage struct {
+	Type         string `json:"Type"`
+	Message      string `json:"Message"`
+	SubscribeURL string `json:"SubscribeURL"`
+}
+
+type sesRecipient struct {
+	EmailAddress   string `json:"emailAddress"`
+	DiagnosticCode string `json:"diagnosticCode"`
+}
+
+type sesNotification struct {
+	NotificationType string `json:"notificationType"`
+	EventType        string `json:"eventType"`
+	Bounce           struct {
+		BounceType        string         `json:"bounceType"`
+		BouncedRecipients []sesRecipient `json:"bouncedRecipients"`
+	} `json:"bounce"`
+	Complaint struct {
+		ComplainedRecipients  []sesRecipient `json:"complainedRecipients"`
+		ComplaintFeedbackType string         `json:"complaintFeedbackType"`
+	} `json:"complaint"`
+}
+
+// parseSESNotification unwraps the SNS envelope, a raw SES notification is read as well
+func parseSESNotification(body []byte) (*BounceNotification, error) {
+	var envelope snsMessage
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, err
+	}
+	switch envelope.Type {
+	case "SubscriptionConfirmation":
+		return &BounceNotification{SubscribeURL: envelope.SubscribeURL}, nil
+	case "Notification":
+		body = []byte(envelope.Message)
+	}
+
+	var notification sesNotification
+	if err := json.Unmarshal(body, &notification); err != nil {
+		return nil, err
+	}
+	kind := notification.NotificationType
+	if kind == "" {
+		kind = notification.EventType
+	}
+
+	result := &BounceNotification{}
+	switch kind {
+	case "Bounce":
+		if notification.Bounce.BounceType != "Permanent" {
+			break
+		}
+		for _, recipient := range notification.Bounce.BouncedRecipients {
+			result.add(recipient.EmailAddress, SuppressionBounce, recipient.DiagnosticCode)
+		}
+	case "Complaint":
+		for _, recipient := range notification.Complaint.ComplainedRecipients {
+			result.add(recipient.EmailAddress, SuppressionComplaint, notification.Complaint.ComplaintFeedbackType)
+		}
+	}
+	return result, nil
+}
+
+func (n *BounceNotification) add(address, reason, detail string) {
+	if address = bareAddress(address); address != "" {
+		n.Events = append(n.Events, BounceEvent{Address: address, Reason: reason, Detail: detail})
+	}
+}
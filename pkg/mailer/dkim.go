@@ -0,0 +1,109 @@
+package mailer
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	configs "backend/pkg/config"
+
+	"github.com/emersion/go-msgauth/dkim"
+)
+
+// DKIMSigner adds a DKIM-Signature to the built messages so receivers can tie them to the sending domain
+type DKIMSigner struct {
+	options *dkim.SignOptions
+}
+
+// NewDKIMSigner loads the RSA or Ed25519 key of the config, it returns nil when signing is disabled
+func NewDKIMSigner(config configs.MailDKIMConfig) (*DKIMSigner, error) {
+	if !config.Enable {
+		return nil, nil
+	}
+	if config.Domain == "" || config.Selector == "" {
+		return nil, errors.New("dkim needs the domain and the selector")
+	}
+	key, err := loadDKIMKey(config)
+	if err != nil {
+		return nil, err
+	}
+	return &DKIMSigner{options: &dkim.SignOptions{
+		Domain:                 config.Domain,
+		Selector:               config.Selector,
+		Signer:                 key,
+		HeaderCanonicalization: dkim.CanonicalizationRelaxed,
+		BodyCanonicalization:   dkim.CanonicalizationRelaxed,
+		HeaderKeys:             config.Headers,
+	}}, nil
+}
+
+func (s *DKIMSigner) Sign(raw []byte) ([]byte, error) {
+	var signed bytes.Buffer
+	if err := dkim.Sign(&signed, bytes.NewReader(raw), s.options); err != nil {
+		return nil, fmt.Errorf("dkim sign: %w", err)
+	}
+	return signed.Bytes(), nil
+}
+
+// loadDKIMKey reads a PEM key, PKCS#8 (RSA or Ed25519) or PKCS#1 (RSA), inline or from a file
+func loadDKIMKey(config configs.MailDKIMConfig) (crypto.Signer, error) {
+	content := []byte(config.PrivateKey)
+	if len(content) == 0 {
+		var err error
+		if content, err = os.ReadFile(config.PrivateKeyFile); err != nil {
+			return nil, fmt.Errorf("dkim key: %w", err)
+		}
+	}
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, errors.New("dkim key is not PEM encoded")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("dkim key: %w", err)
+	}
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		return key, nil
+	case ed25519.PrivateKey:
+		return key, nil
+	}
+	return nil, fmt.Errorf("dkim key type %T is not supported", key)
+}
+
+// Composer turns messages into the MIME handed to the transports, signed when DKIM is enabled
+type Composer struct {
+	from string
+	dkim *DKIMSigner
+}
+
+func NewComposer(appConfig *configs.AppConfig) (*Composer, error) {
+	signer, err := NewDKIMSigner(appConfig.Mail.DKIM)
+	if err != nil {
+		return nil, err
+	}
+	return &Composer{from: sender(appConfig), dkim: signer}, nil
+}
+
+// From is the default sender
+func (c *Composer) From() string {
+	return c.from
+}
+
+func (c *Composer) Compose(message *Message, now time.Time) ([]byte, error) {
+	raw, err := BuildMIME(message, c.from, now)
+	if err != nil || c.dkim == nil {
+		return raw, err
+	}
+	return c.dkim.Sign(raw)
+}
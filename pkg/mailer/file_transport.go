@@ -0,0 +1,44 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const defaultMailDir = "../mails"
+
+// FileTransport writes every mail as an .eml file, handy to open the mails of a local run in a mail client
+type FileTransport struct {
+	dir      string
+	composer *Composer
+}
+
+func NewFileTransport(dir string, composer *Composer) (*FileTransport, error) {
+	if dir == "" {
+		dir = defaultMailDir
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create mail dir %s: %w", dir, err)
+	}
+	return &FileTransport{dir: dir, composer: composer}, nil
+}
+
+func (t *FileTransport) Send(ctx context.Context, message *Message) error {
+	now := time.Now()
+	raw, err := t.composer.Compose(message, now)
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", now.UTC().Format("20060102T150405.000"), uuid.NewString())
+	// written aside then renamed so a watcher never picks up a partial file
+	path := filepath.Join(t.dir, name)
+	if err = os.WriteFile(path+".tmp", raw, 0o600); err != nil {
+		return fmt.Errorf("failed to write mail %s: %w", path, err)
+	}
+	return os.Rename(path+".tmp", path)
+}
@@ -0,0 +1,47 @@
+package mailer
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	htmlHiddenBlocks = regexp.MustCompile(`(?is)<(head|style|script)[^>]*>.*?</(head|style|script)>`)
+	htmlLinks        = regexp.MustCompile(`(?is)<a\s[^>]*href\s*=\s*["']([^"']+)["'][^>]*>(.*?)</a\s*>`)
+	htmlLineBreaks   = regexp.MustCompile(`(?i)<br\s*/?>`)
+	htmlBlockEnds    = regexp.MustCompile(`(?i)</(p|div|h[1-6]|li|tr|table|ul|ol|blockquote)\s*>`)
+	htmlListItems    = regexp.MustCompile(`(?i)<li[^>]*>`)
+	htmlTags         = regexp.MustCompile(`(?s)<[^>]*>`)
+	htmlSpaces       = regexp.MustCompile(`\s+`)
+	textSpaces       = regexp.MustCompile(`[ \t\f\v]+`)
+	textBlankLines   = regexp.MustCompile(`\n{3,}`)
+)
+
+// HTMLToText derives the plain text alternative of an HTML body: links keep their target, blocks become lines
+func HTMLToText(body string) string {
+	text := htmlHiddenBlocks.ReplaceAllString(body, "")
+	// line breaks of the source are plain spaces in HTML, only tags break lines
+	text = htmlSpaces.ReplaceAllString(text, " ")
+	text = htmlLinks.ReplaceAllStringFunc(text, func(link string) string {
+		match := htmlLinks.FindStringSubmatch(link)
+		label := strings.TrimSpace(htmlTags.ReplaceAllString(match[2], ""))
+		if label == "" || label == match[1] {
+			return match[1]
+		}
+		return label + " (" + match[1] + ")"
+	})
+	text = htmlLineBreaks.ReplaceAllString(text, "\n")
+	text = htmlBlockEnds.ReplaceAllString(text, "\n\n")
+	text = htmlListItems.ReplaceAllString(text, "- ")
+	text = htmlTags.ReplaceAllString(text, "")
+	text = html.UnescapeString(text)
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(textSpaces.ReplaceAllString(line, " "))
+	}
+	text = strings.Join(lines, "\n")
+	text = textBlankLines.ReplaceAllString(text, "\n\n")
+	return strings.TrimSpace(text) + "\n"
+}
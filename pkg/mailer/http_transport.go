@@ -0,0 +1,333 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+
+	configs "backend/pkg/config"
+)
+
+const (
+	HTTP_PROVIDER_SENDGRID = "sendgrid"
+	HTTP_PROVIDER_MAILGUN  = "mailgun"
+	HTTP_PROVIDER_SES      = "ses"
+
+	defaultHTTPTimeout = 15 * time.Second
+	maxErrorBodyBytes  = 1024
+)
+
+// HTTPTransport delivers through an email API. SendGrid gets a JSON mail and signs it with the domain authenticated
+// there, Mailgun and SES get the composed MIME so both keep the exact same content and DKIM signature as SMTP.
+type HTTPTransport struct {
+	provider string
+	config   configs.MailHTTPConfig
+	baseURL  string
+	composer *Composer
+	client   *http.Client
+}
+
+func NewHTTPTransport(config configs.MailHTTPConfig, composer *Composer) (*HTTPTransport, error) {
+	provider := strings.ToLower(config.Provider)
+	baseURL := config.BaseURL
+	switch provider {
+	case HTTP_PROVIDER_SENDGRID:
+		if baseURL == "" {
+			baseURL = "https://api.sendgrid.com"
+		}
+	case HTTP_PROVIDER_MAILGUN:
+		if config.Domain == "" {
+			return nil, fmt.Errorf("mailgun needs the sending domain")
+		}
+		if baseURL == "" {
+			baseURL = "https://api.mailgun.net"
+		}
+	case HTTP_PROVIDER_SES:
+		if config.Region == "" || config.AccessKeyId == "" || config.SecretAccessKey == "" {
+			return nil, fmt.Errorf("ses needs the region and access keys")
+		}
+		if baseURL == "" {
+			baseURL = fmt.Sprintf("https://email.%s.amazonaws.com", config.Region)
+		}
+	default:
+		return nil, fmt.Errorf("unknown mail http provider %q", config.Provider)
+	}
+
+	return &HTTPTransport{
+		provider: provider,
+		config:   config,
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		composer: composer,
+		client:   &http.Client{Timeout: seconds(config.Timeout, defaultHTTPTimeout)},
+	}, nil
+}
+
+func (t *HTTPTransport) Send(ctx context.Context, message *Message) error {
+	var request *http.Request
+	var err error
+	switch t.provider {
+	case HTTP_PROVIDER_SENDGRID:
+		request, err = t.sendGridRequest(ctx, message)
+	case HTTP_PROVIDER_MAILGUN:
+		request, err = t.mailgunRequest(ctx, message)
+	default:
+		request, err = t.sesRequest(ctx, message)
+	}
+	if err != nil {
+		return err
+	}
+
+	response, err := t.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("%s request failed: %w", t.provider, err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(response.Body, maxErrorBodyBytes))
+		return fmt.Errorf("%s responded %d: %s", t.provider, response.StatusCode, strings.TrimSpace(string(body)))
+	}
+	_, _ = io.Copy(io.Discard, response.Body)
+	return nil
+}
+
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+type sendGridPersonalization struct {
+	To  []sendGridAddress `json:"to"`
+	Cc  []sendGridAddress `json:"cc,omitempty"`
+	Bcc []sendGridAddress `json:"bcc,omitempty"`
+}
+
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sendGridAttachment struct {
+	Content     string `json:"content"`
+	Type        string `json:"type,omitempty"`
+	Filename    string `json:"filename"`
+	Disposition string `json:"disposition"`
+	ContentId   string `json:"content_id,omitempty"`
+}
+
+type sendGridMail struct {
+	Personalizations []sendGridPersonalization `json:"personalizations"`
+	From             sendGridAddress           `json:"from"`
+	ReplyTo          *sendGridAddress          `json:"reply_to,omitempty"`
+	Subject          string                    `json:"subject"`
+	Content          []sendGridContent         `json:"content"`
+	Attachments      []sendGridAttachment      `json:"attachments,omitempty"`
+	Headers          map[string]string         `json:"headers,omitempty"`
+}
+
+func (t *HTTPTransport) sendGridRequest(ctx context.Context, message *Message) (*http.Request, error) {
+	from := message.From
+	if from == "" {
+		from = t.composer.From()
+	}
+	sender, err := sendGridAddresses(from)
+	if err != nil {
+		return nil, err
+	}
+	to, err := sendGridAddresses(message.To)
+	if err != nil {
+		return nil, err
+	}
+	cc, err := sendGridAddresses(message.Cc...)
+	if err != nil {
+		return nil, err
+	}
+	bcc, err := sendGridAddresses(message.Bcc...)
+	if err != nil {
+		return nil, err
+	}
+
+	mailBody := sendGridMail{
+		Personalizations: []sendGridPersonalization{{To: to, Cc: cc, Bcc: bcc}},
+		From:             sender[0],
+		Subject:          message.Subject,
+		Headers:          message.Headers,
+	}
+	if message.ReplyTo != "" {
+		replyTo, err := sendGridAddresses(message.ReplyTo)
+		if err != nil {
+			return nil, err
+		}
+		mailBody.ReplyTo = &replyTo[0]
+	}
+	// SendGrid wants text/plain ahead of text/html
+	text := message.Text
+	if text == "" && message.HTML != "" {
+		text = HTMLToText(message.HTML)
+	}
+	if text != "" {
+		mailBody.Content = append(mailBody.Content, sendGridContent{Type: "text/plain", Value: text})
+	}
+	if message.HTML != "" {
+		mailBody.Content = append(mailBody.Content, sendGridContent{Type: "text/html", Value: message.HTML})
+	}
+	for _, attachment := range message.Attachments {
+		disposition := "attachment"
+		if attachment.ContentId != "" {
+			disposition = "inline"
+		}
+		mailBody.Attachments = append(mailBody.Attachments, sendGridAttachment{
+			Content:     base64.StdEncoding.EncodeToString(attachment.Data),
+			Type:        attachment.ContentType,
+			Filename:    attachment.Filename,
+			Disposition: disposition,
+			ContentId:   attachment.ContentId,
+		})
+	}
+
+	body, err := json.Marshal(mailBody)
+	if err != nil {
+		return nil, err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, t.baseURL+"/v3/mail/send", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "Bearer "+t.config.ApiKey)
+	return request, nil
+}
+
+func sendGridAddresses(addresses ...string) ([]sendGridAddress, error) {
+	result := make([]sendGridAddress, 0, len(addresses))
+	for _, address := range addresses {
+		parsed, err := mail.ParseAddress(address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address %q: %w", address, err)
+		}
+		result = append(result, sendGridAddress{Email: parsed.Address, Name: parsed.Name})
+	}
+	return result, nil
+}
+
+func (t *HTTPTransport) mailgunRequest(ctx context.Context, message *Message) (*http.Request, error) {
+	raw, err := t.composer.Compose(message, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	// Bcc is absent from the MIME headers, the to field carries every envelope recipient
+	if err = form.WriteField("to", strings.Join(message.Recipients(), ",")); err != nil {
+		return nil, err
+	}
+	file, err := form.CreateFormFile("message", "message.eml")
+	if err != nil {
+		return nil, err
+	}
+	if _, err = file.Write(raw); err != nil {
+		return nil, err
+	}
+	if err = form.Close(); err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/v3/%s/messages.mime", t.baseURL, t.config.Domain)
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, &body)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", form.FormDataContentType())
+	request.SetBasicAuth("api", t.config.ApiKey)
+	return request, nil
+}
+
+type sesDestination struct {
+	ToAddresses  []string `json:"ToAddresses"`
+	CcAddresses  []string `json:"CcAddresses,omitempty"`
+	BccAddresses []string `json:"BccAddresses,omitempty"`
+}
+
+type sesRawContent struct {
+	Raw struct {
+		Data []byte `json:"Data"`
+	} `json:"Raw"`
+}
+
+type sesMail struct {
+	Destination sesDestination `json:"Destination"`
+	Content     sesRawContent  `json:"Content"`
+}
+
+func (t *HTTPTransport) sesRequest(ctx context.Context, message *Message) (*http.Request, error) {
+	now := time.Now()
+	raw, err := t.composer.Compose(message, now)
+	if err != nil {
+		return nil, err
+	}
+	mailBody := sesMail{Destination: sesDestination{ToAddresses: []string{message.To}, CcAddresses: message.Cc, BccAddresses: message.Bcc}}
+	mailBody.Content.Raw.Data = raw
+
+	body, err := json.Marshal(mailBody)
+	if err != nil {
+		return nil, err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, t.baseURL+"/v2/email/outbound-emails", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	t.signSES(request, body, now)
+	return request, nil
+}
+
+// signSES signs the request with AWS Signature Version 4
+func (t *HTTPTransport) signSES(request *http.Request, body []byte, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	payloadHash := sha256Hex(body)
+	request.Header.Set("X-Amz-Date", amzDate)
+	request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "content-type;host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := "content-type:" + request.Header.Get("Content-Type") + "\n" +
+		"host:" + request.URL.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+	canonicalRequest := strings.Join([]string{
+		request.Method, request.URL.EscapedPath(), request.URL.RawQuery, canonicalHeaders, signedHeaders, payloadHash,
+	}, "\n")
+
+	scope := date + "/" + t.config.Region + "/ses/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+	key := hmacSHA256([]byte("AWS4"+t.config.SecretAccessKey), date)
+	key = hmacSHA256(key, t.config.Region)
+	key = hmacSHA256(key, "ses")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	request.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		t.config.AccessKeyId, scope, signedHeaders, signature))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
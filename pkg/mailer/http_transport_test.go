@@ -0,0 +1,276 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	configs "backend/pkg/config"
+)
+
+func testMessage() *Message {
+	return &Message{
+		To:      "Jane Doe <jane@example.com>",
+		Cc:      []string{"cc@example.com"},
+		Bcc:     []string{"bcc@example.com"},
+		Subject: "Welcome",
+		Text:    "Hello Jane",
+		HTML:    "<p>Hello Jane</p>",
+	}
+}
+
+// capturedRequest is a copy of what the server received, the *http.Request itself stays owned by the server
+type capturedRequest struct {
+	Method string
+	Path   string
+	Query  string
+	Host   string
+	Header http.Header
+	Body   []byte
+}
+
+// rebuild returns a fresh request carrying the captured data, for helpers such as BasicAuth or ParseMultipartForm
+func (c *capturedRequest) rebuild() *http.Request {
+	request := httptest.NewRequest(c.Method, c.Path, bytes.NewReader(c.Body))
+	request.Header = c.Header.Clone()
+	return request
+}
+
+// captureServer copies every request it receives, then answers with status
+func captureServer(t *testing.T, status int) (*httptest.Server, func() *capturedRequest) {
+	t.Helper()
+	requests := make(chan *capturedRequest, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- &capturedRequest{
+			Method: r.Method,
+			Path:   r.URL.Path,
+			Query:  r.URL.RawQuery,
+			Host:   r.Host,
+			Header: r.Header.Clone(),
+			Body:   body,
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"message":"stub"}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, func() *capturedRequest {
+		select {
+		case request := <-requests:
+			return request
+		default:
+			t.Fatal("the server received no request")
+			return nil
+		}
+	}
+}
+
+func newTestHTTPTransport(t *testing.T, config configs.MailHTTPConfig) *HTTPTransport {
+	t.Helper()
+	transport, err := NewHTTPTransport(config, &Composer{from: "App <no-reply@app.test>"})
+	if err != nil {
+		t.Fatalf("new transport: %v", err)
+	}
+	return transport
+}
+
+func TestHTTPTransportSendGrid(t *testing.T) {
+	server, captured := captureServer(t, http.StatusAccepted)
+	transport := newTestHTTPTransport(t, configs.MailHTTPConfig{Provider: "sendgrid", BaseURL: server.URL, ApiKey: "sg-key"})
+
+	if err := transport.Send(context.Background(), testMessage()); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	request := captured()
+	if request.Method != http.MethodPost || request.Path != "/v3/mail/send" {
+		t.Fatalf("request %s %s", request.Method, request.Path)
+	}
+	if got := request.Header.Get("Authorization"); got != "Bearer sg-key" {
+		t.Errorf("authorization %q", got)
+	}
+	if got := request.Header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("content type %q", got)
+	}
+
+	var mail sendGridMail
+	if err := json.Unmarshal(request.Body, &mail); err != nil {
+		t.Fatalf("body is not json: %v", err)
+	}
+	if mail.From != (sendGridAddress{Email: "no-reply@app.test", Name: "App"}) {
+		t.Errorf("from %+v", mail.From)
+	}
+	if len(mail.Personalizations) != 1 {
+		t.Fatalf("personalizations %+v", mail.Personalizations)
+	}
+	personalization := mail.Personalizations[0]
+	if len(personalization.To) != 1 || personalization.To[0] != (sendGridAddress{Email: "jane@example.com", Name: "Jane Doe"}) {
+		t.Errorf("to %+v", personalization.To)
+	}
+	if len(personalization.Cc) != 1 || personalization.Cc[0].Email != "cc@example.com" {
+		t.Errorf("cc %+v", personalization.Cc)
+	}
+	if len(personalization.Bcc) != 1 || personalization.Bcc[0].Email != "bcc@example.com" {
+		t.Errorf("bcc %+v", personalization.Bcc)
+	}
+	if mail.Subject != "Welcome" {
+		t.Errorf("subject %q", mail.Subject)
+	}
+	want := []sendGridContent{{Type: "text/plain", Value: "Hello Jane"}, {Type: "text/html", Value: "<p>Hello Jane</p>"}}
+	if len(mail.Content) != len(want) || mail.Content[0] != want[0] || mail.Content[1] != want[1] {
+		t.Errorf("content %+v", mail.Content)
+	}
+}
+
+func TestHTTPTransportSendGridErrorStatus(t *testing.T) {
+	server, _ := captureServer(t, http.StatusBadRequest)
+	transport := newTestHTTPTransport(t, configs.MailHTTPConfig{Provider: "sendgrid", BaseURL: server.URL, ApiKey: "sg-key"})
+
+	err := transport.Send(context.Background(), testMessage())
+	if err == nil || !strings.Contains(err.Error(), "400") || !strings.Contains(err.Error(), "stub") {
+		t.Fatalf("error %v", err)
+	}
+}
+
+func TestHTTPTransportMailgun(t *testing.T) {
+	server, captured := captureServer(t, http.StatusOK)
+	transport := newTestHTTPTransport(t, configs.MailHTTPConfig{Provider: "mailgun", BaseURL: server.URL, ApiKey: "mg-key", Domain: "mg.app.test"})
+
+	if err := transport.Send(context.Background(), testMessage()); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	received := captured()
+	if received.Method != http.MethodPost || received.Path != "/v3/mg.app.test/messages.mime" {
+		t.Fatalf("request %s %s", received.Method, received.Path)
+	}
+	request := received.rebuild()
+	user, password, ok := request.BasicAuth()
+	if !ok || user != "api" || password != "mg-key" {
+		t.Errorf("basic auth %q %q %v", user, password, ok)
+	}
+
+	if err := request.ParseMultipartForm(1 << 20); err != nil {
+		t.Fatalf("body is not multipart: %v", err)
+	}
+	if got := request.MultipartForm.Value["to"]; len(got) != 1 || got[0] != "Jane Doe <jane@example.com>,cc@example.com,bcc@example.com" {
+		t.Errorf("to %q", got)
+	}
+	files := request.MultipartForm.File["message"]
+	if len(files) != 1 {
+		t.Fatalf("message files %d", len(files))
+	}
+	file, err := files[0].Open()
+	if err != nil {
+		t.Fatalf("open message: %v", err)
+	}
+	defer file.Close()
+	raw, _ := io.ReadAll(file)
+	for _, header := range []string{"From: ", "To: ", "Subject: Welcome"} {
+		if !strings.Contains(string(raw), header) {
+			t.Errorf("mime lacks %q", header)
+		}
+	}
+	if strings.Contains(string(raw), "bcc@example.com") {
+		t.Error("mime leaks the bcc recipient")
+	}
+}
+
+func TestHTTPTransportSES(t *testing.T) {
+	server, captured := captureServer(t, http.StatusOK)
+	config := configs.MailHTTPConfig{
+		Provider:        "ses",
+		BaseURL:         server.URL,
+		Region:          "eu-west-1",
+		AccessKeyId:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	}
+	transport := newTestHTTPTransport(t, config)
+
+	if err := transport.Send(context.Background(), testMessage()); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	request := captured()
+	if request.Method != http.MethodPost || request.Path != "/v2/email/outbound-emails" {
+		t.Fatalf("request %s %s", request.Method, request.Path)
+	}
+
+	var mail sesMail
+	if err := json.Unmarshal(request.Body, &mail); err != nil {
+		t.Fatalf("body is not json: %v", err)
+	}
+	if len(mail.Destination.ToAddresses) != 1 || mail.Destination.ToAddresses[0] != "Jane Doe <jane@example.com>" {
+		t.Errorf("to %+v", mail.Destination.ToAddresses)
+	}
+	if len(mail.Destination.BccAddresses) != 1 || mail.Destination.BccAddresses[0] != "bcc@example.com" {
+		t.Errorf("bcc %+v", mail.Destination.BccAddresses)
+	}
+	if !strings.Contains(string(mail.Content.Raw.Data), "Subject: Welcome") {
+		t.Errorf("raw data is not the composed mime")
+	}
+
+	amzDate := request.Header.Get("X-Amz-Date")
+	if _, err := time.Parse("20060102T150405Z", amzDate); err != nil {
+		t.Fatalf("x-amz-date %q: %v", amzDate, err)
+	}
+	payloadSum := sha256.Sum256(request.Body)
+	payloadHash := hex.EncodeToString(payloadSum[:])
+	if got := request.Header.Get("X-Amz-Content-Sha256"); got != payloadHash {
+		t.Errorf("payload hash %q, want %q", got, payloadHash)
+	}
+
+	scope := amzDate[:8] + "/eu-west-1/ses/aws4_request"
+	signedHeaders := "content-type;host;x-amz-content-sha256;x-amz-date"
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/" + scope + ", SignedHeaders=" + signedHeaders +
+		", Signature=" + expectedSESSignature(request, payloadHash, scope, config.SecretAccessKey)
+	if got := request.Header.Get("Authorization"); got != want {
+		t.Errorf("authorization\n got %s\nwant %s", got, want)
+	}
+}
+
+// expectedSESSignature recomputes the SigV4 signature from the request as the server received it
+func expectedSESSignature(request *capturedRequest, payloadHash string, scope string, secret string) string {
+	amzDate := request.Header.Get("X-Amz-Date")
+	canonicalRequest := "POST\n" + request.Path + "\n" + request.Query + "\n" +
+		"content-type:" + request.Header.Get("Content-Type") + "\n" +
+		"host:" + request.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + amzDate + "\n\n" +
+		"content-type;host;x-amz-content-sha256;x-amz-date\n" +
+		payloadHash
+	canonicalSum := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(canonicalSum[:])
+
+	sign := func(key []byte, data string) []byte {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(data))
+		return mac.Sum(nil)
+	}
+	key := []byte("AWS4" + secret)
+	for _, part := range strings.Split(scope, "/") {
+		key = sign(key, part)
+	}
+	return hex.EncodeToString(sign(key, stringToSign))
+}
+
+func TestNewHTTPTransportRejectsIncompleteConfig(t *testing.T) {
+	for _, config := range []configs.MailHTTPConfig{
+		{Provider: "mailgun", ApiKey: "key"},
+		{Provider: "ses", Region: "eu-west-1"},
+		{Provider: "postmark"},
+	} {
+		if _, err := NewHTTPTransport(config, &Composer{}); err == nil {
+			t.Errorf("%s accepted %+v", config.Provider, config)
+		}
+	}
+}
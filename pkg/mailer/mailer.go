@@ -2,81 +2,59 @@ package mailer
 
 import (
 	"context"
-	"crypto/tls"
-	"fmt"
-	"net/smtp"
-
-	configs "backend/pkg/config"
 )
 
 type Mailer interface {
 	SendText(ctx context.Context, to string, subject string, body string) error
 	SendHTML(ctx context.Context, to string, subject string, htmlBody string) error
+	// Send delivers a message built with the full set of options, attachments and headers included
+	Send(ctx context.Context, message *Message) error
 }
 
-type SMTPMailer struct {
-	Host     string
-	Port     string
-	Username string
-	Password string
-	From     string
+// Message is an email as handed to a transport. From falls back to the configured sender,
+// Text is derived from HTML when only HTML is given.
+type Message struct {
+	From        string            `json:"from,omitempty"`
+	To          string            `json:"to"`
+	Cc          []string          `json:"cc,omitempty"`
+	Bcc         []string          `json:"bcc,omitempty"`
+	ReplyTo     string            `json:"replyTo,omitempty"`
+	Subject     string            `json:"subject"`
+	Text        string            `json:"text,omitempty"`
+	HTML        string            `json:"html,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Attachments []Attachment      `json:"attachments,omitempty"`
 }
 
-func NewSMTPMailer(appConfig *configs.AppConfig) Mailer {
-	config := appConfig.Smtp
-
-	return &SMTPMailer{
-		Host:     config.Host,
-		Port:     config.Port,
-		Username: config.UserName,
-		Password: config.Password,
-		From:     config.From,
-	}
+// Attachment is a file sent along the message, setting ContentId makes it an inline image referenced as cid:<ContentId>
+type Attachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"contentType"`
+	Data        []byte `json:"data"`
+	ContentId   string `json:"contentId,omitempty"`
 }
 
-func (m *SMTPMailer) sendEmail(to, subject, body, contentType string) error {
-	msg := fmt.Sprintf("From: %s\nTo: %s\nSubject: %s\nMIME-version: 1.0;\nContent-Type: %s\n\n%s",
-		m.From, to, subject, contentType, body)
-
-	addr := m.Host + ":" + m.Port
-	tlsconfig := &tls.Config{
-		//InsecureSkipVerify: true,
-		ServerName: m.Host,
-	}
-	conn, err := tls.Dial("tcp", addr, tlsconfig)
-
-	client, err := smtp.NewClient(conn, m.Host)
-	if err != nil {
-		return fmt.Errorf("lỗi tạo SMTP client: %v", err)
-	}
-	defer client.Quit()
-
-	auth := smtp.PlainAuth("", m.Username, m.Password, m.Host)
+// Recipients are the envelope recipients, Bcc included
+func (m *Message) Recipients() []string {
+	recipients := make([]string, 0, 1+len(m.Cc)+len(m.Bcc))
+	recipients = append(recipients, m.To)
+	recipients = append(recipients, m.Cc...)
+	return append(recipients, m.Bcc...)
+}
 
-	if err = client.Auth(auth); err != nil {
-		return fmt.Errorf("lỗi xác thực SMTP: %v", err)
-	}
-	if err = client.Mail(m.From); err != nil {
-		return fmt.Errorf("lỗi MAIL FROM: %v", err)
-	}
-	if err = client.Rcpt(to); err != nil {
-		return fmt.Errorf("lỗi RCPT TO: %v", err)
-	}
-	writer, err := client.Data()
-	if err != nil {
-		return fmt.Errorf("lỗi mở writer: %v", err)
-	}
-	_, err = writer.Write([]byte(msg))
-	if err != nil {
-		return fmt.Errorf("lỗi ghi nội dung email: %v", err)
-	}
-	return writer.Close()
+// Transport delivers a message right away
+type Transport interface {
+	Send(ctx context.Context, message *Message) error
 }
 
-func (m *SMTPMailer) SendText(ctx context.Context, to, subject, body string) error {
-	return m.sendEmail(to, subject, body, "text/plain; charset=UTF-8")
+type idempotencyKey struct{}
+
+// WithIdempotencyKey makes the mails sent with ctx enqueued once per key, a retried request does not mail twice
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKey{}, key)
 }
 
-func (m *SMTPMailer) SendHTML(ctx context.Context, to, subject, htmlBody string) error {
-	return m.sendEmail(to, subject, htmlBody, "text/html; charset=UTF-8")
+func IdempotencyKeyFromContext(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(idempotencyKey{}).(string)
+	return key, ok && key != ""
 }
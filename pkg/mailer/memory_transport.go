@@ -0,0 +1,87 @@
+package mailer
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	configs "backend/pkg/config"
+
+	"github.com/google/uuid"
+)
+
+const defaultMemoryMaxMessages = 200
+
+// CapturedMail is a mail kept by the memory transport
+type CapturedMail struct {
+	Id      string
+	Message Message
+	Raw     []byte
+	SentAt  time.Time
+}
+
+// MemoryTransport keeps the latest mails in process instead of delivering them, the oldest are dropped once full
+type MemoryTransport struct {
+	mu          sync.RWMutex
+	composer    *Composer
+	maxMessages int
+	mails       []CapturedMail
+}
+
+func NewMemoryTransport(appConfig *configs.AppConfig, composer *Composer) *MemoryTransport {
+	return &MemoryTransport{
+		composer:    composer,
+		maxMessages: positive(appConfig.Mail.Memory.MaxMessages, defaultMemoryMaxMessages),
+	}
+}
+
+func (t *MemoryTransport) Send(ctx context.Context, message *Message) error {
+	now := time.Now()
+	// built like any other transport would, an invalid message fails here as well
+	raw, err := t.composer.Compose(message, now)
+	if err != nil {
+		return err
+	}
+	captured := CapturedMail{Id: uuid.NewString(), Message: *message, Raw: raw, SentAt: now}
+	if captured.Message.From == "" {
+		captured.Message.From = t.composer.From()
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.mails = append(t.mails, captured)
+	if len(t.mails) > t.maxMessages {
+		t.mails = t.mails[len(t.mails)-t.maxMessages:]
+	}
+	return nil
+}
+
+// Mails returns the captured mails, newest first
+func (t *MemoryTransport) Mails() []CapturedMail {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	mails := make([]CapturedMail, 0, len(t.mails))
+	for i := len(t.mails) - 1; i >= 0; i-- {
+		mails = append(mails, t.mails[i])
+	}
+	return mails
+}
+
+func (t *MemoryTransport) Find(id string) (CapturedMail, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	for _, mail := range t.mails {
+		if mail.Id == id {
+			return mail, true
+		}
+	}
+	return CapturedMail{}, false
+}
+
+func (t *MemoryTransport) Clear() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.mails = nil
+}
@@ -0,0 +1,259 @@
+package mailer
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	crlf             = "\r\n"
+	base64LineLength = 76
+)
+
+// headers set by the builder, custom headers can not override them
+var reservedHeaders = map[string]bool{
+	"From": true, "To": true, "Cc": true, "Bcc": true, "Reply-To": true, "Subject": true, "Date": true,
+	"Message-Id": true, "Mime-Version": true, "Content-Type": true, "Content-Transfer-Encoding": true,
+}
+
+// mimePart is a leaf with an encoded body or a multipart container of children
+type mimePart struct {
+	header    textproto.MIMEHeader
+	body      []byte
+	mediaType string
+	children  []*mimePart
+}
+
+// BuildMIME renders message as an RFC 5322 message with CRLF line endings. The body is multipart/alternative
+// when it has HTML, wrapped in multipart/related for inline images and in multipart/mixed for attachments.
+// Bcc recipients are left out of the headers.
+func BuildMIME(message *Message, defaultFrom string, now time.Time) ([]byte, error) {
+	from := message.From
+	if from == "" {
+		from = defaultFrom
+	}
+	fromAddress, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from address %q: %w", from, err)
+	}
+	if _, err := formatAddresses(message.Bcc); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	header := func(name, value string) {
+		buf.WriteString(name + ": " + value + crlf)
+	}
+	header("From", fromAddress.String())
+	to, err := formatAddresses([]string{message.To})
+	if err != nil {
+		return nil, err
+	}
+	header("To", to)
+	if len(message.Cc) > 0 {
+		cc, err := formatAddresses(message.Cc)
+		if err != nil {
+			return nil, err
+		}
+		header("Cc", cc)
+	}
+	if message.ReplyTo != "" {
+		replyTo, err := formatAddresses([]string{message.ReplyTo})
+		if err != nil {
+			return nil, err
+		}
+		header("Reply-To", replyTo)
+	}
+	if err := validHeaderValue(message.Subject); err != nil {
+		return nil, err
+	}
+	header("Subject", mime.QEncoding.Encode("utf-8", message.Subject))
+	header("Date", now.Format(time.RFC1123Z))
+	header("Message-ID", messageId(fromAddress.Address))
+	header("MIME-Version", "1.0")
+
+	names := make([]string, 0, len(message.Headers))
+	for name := range message.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		canonical := textproto.CanonicalMIMEHeaderKey(name)
+		if reservedHeaders[canonical] {
+			return nil, fmt.Errorf("header %s is set by the mailer", name)
+		}
+		if err := validHeaderValue(name + message.Headers[name]); err != nil {
+			return nil, err
+		}
+		header(canonical, mime.QEncoding.Encode("utf-8", message.Headers[name]))
+	}
+
+	rootHeader, body, err := render(bodyPart(message))
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range sortedKeys(rootHeader) {
+		header(name, rootHeader.Get(name))
+	}
+	buf.WriteString(crlf)
+	buf.Write(body)
+	return buf.Bytes(), nil
+}
+
+// bodyPart lays out the part tree of message
+func bodyPart(message *Message) *mimePart {
+	text := message.Text
+	if text == "" && message.HTML != "" {
+		text = HTMLToText(message.HTML)
+	}
+
+	root := textPart("text/plain", text)
+	if message.HTML != "" {
+		root = &mimePart{mediaType: "multipart/alternative", children: []*mimePart{root, textPart("text/html", message.HTML)}}
+	}
+
+	var inline, attached []*mimePart
+	for _, attachment := range message.Attachments {
+		if attachment.ContentId != "" {
+			inline = append(inline, attachmentPart(attachment))
+		} else {
+			attached = append(attached, attachmentPart(attachment))
+		}
+	}
+	if len(inline) > 0 {
+		root = &mimePart{mediaType: "multipart/related", children: append([]*mimePart{root}, inline...)}
+	}
+	if len(attached) > 0 {
+		root = &mimePart{mediaType: "multipart/mixed", children: append([]*mimePart{root}, attached...)}
+	}
+	return root
+}
+
+func render(part *mimePart) (textproto.MIMEHeader, []byte, error) {
+	if part.mediaType == "" {
+		return part.header, part.body, nil
+	}
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	for _, child := range part.children {
+		header, body, err := render(child)
+		if err != nil {
+			return nil, nil, err
+		}
+		childWriter, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, err := childWriter.Write(body); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, nil, err
+	}
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", mime.FormatMediaType(part.mediaType, map[string]string{"boundary": writer.Boundary()}))
+	return header, buf.Bytes(), nil
+}
+
+func textPart(mediaType string, content string) *mimePart {
+	var buf bytes.Buffer
+	writer := quotedprintable.NewWriter(&buf)
+	_, _ = writer.Write([]byte(content))
+	_ = writer.Close()
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", mime.FormatMediaType(mediaType, map[string]string{"charset": "UTF-8"}))
+	header.Set("Content-Transfer-Encoding", "quoted-printable")
+	return &mimePart{header: header, body: buf.Bytes()}
+}
+
+func attachmentPart(attachment Attachment) *mimePart {
+	contentType := attachment.ContentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(attachment.Filename))
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType, params = "application/octet-stream", map[string]string{}
+	}
+	params["name"] = attachment.Filename
+
+	disposition := "attachment"
+	header := textproto.MIMEHeader{}
+	if attachment.ContentId != "" {
+		disposition = "inline"
+		header.Set("Content-ID", "<"+strings.Trim(attachment.ContentId, "<>")+">")
+	}
+	header.Set("Content-Type", mime.FormatMediaType(mediaType, params))
+	header.Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": attachment.Filename}))
+	header.Set("Content-Transfer-Encoding", "base64")
+	return &mimePart{header: header, body: wrapBase64(attachment.Data)}
+}
+
+func wrapBase64(data []byte) []byte {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	var buf bytes.Buffer
+	for len(encoded) > base64LineLength {
+		buf.WriteString(encoded[:base64LineLength] + crlf)
+		encoded = encoded[base64LineLength:]
+	}
+	buf.WriteString(encoded + crlf)
+	return buf.Bytes()
+}
+
+// formatAddresses validates addresses and encodes their display names (RFC 2047)
+func formatAddresses(addresses []string) (string, error) {
+	formatted := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		parsed, err := mail.ParseAddress(address)
+		if err != nil {
+			return "", fmt.Errorf("invalid address %q: %w", address, err)
+		}
+		formatted = append(formatted, parsed.String())
+	}
+	return strings.Join(formatted, ", "), nil
+}
+
+// validHeaderValue rejects line breaks, they would let a value inject headers
+func validHeaderValue(value string) error {
+	if strings.ContainsAny(value, "\r\n") {
+		return fmt.Errorf("header value %q contains a line break", value)
+	}
+	return nil
+}
+
+func messageId(from string) string {
+	domain := "localhost"
+	if at := strings.LastIndex(from, "@"); at >= 0 && at < len(from)-1 {
+		domain = from[at+1:]
+	}
+	random := make([]byte, 16)
+	_, _ = rand.Read(random)
+	return fmt.Sprintf("<%d.%s@%s>", time.Now().UnixNano(), hex.EncodeToString(random), domain)
+}
+
+func sortedKeys(header textproto.MIMEHeader) []string {
+	keys := make([]string, 0, len(header))
+	for key := range header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
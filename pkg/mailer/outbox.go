@@ -0,0 +1,120 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"backend/pkg/entity"
+
+	"github.com/google/uuid"
+)
+
+const (
+	OutboxPending = "PENDING"
+	OutboxSending = "SENDING"
+	OutboxSent    = "SENT"
+	OutboxDead    = "DEAD"
+)
+
+// OutboxMessage is a mail waiting for delivery. Recipient and payload are encrypted as they carry addresses and one time codes.
+type OutboxMessage struct {
+	entity.BaseAuditTrackingEntity
+	IdempotencyKey string     `json:"idempotencyKey" gorm:"type:varchar(256);not null;uniqueIndex;"`
+	Recipient      string     `json:"recipient" gorm:"type:text;not null;serializer:encrypted;"`
+	Subject        string     `json:"subject" gorm:"type:varchar(512);not null;"`
+	Payload        Message    `json:"-" gorm:"type:text;not null;serializer:encrypted;"`
+	Status         string     `json:"status" gorm:"type:varchar(20);not null;index:idx_mail_outbox_due,priority:1;"`
+	Attempts       int        `json:"attempts" gorm:"not null;default:0;"`
+	NextAttemptAt  time.Time  `json:"nextAttemptAt" gorm:"not null;index:idx_mail_outbox_due,priority:2;"`
+	LockedUntil    *time.Time `json:"lockedUntil,omitempty" gorm:"null;"`
+	// LeaseId identifies the claim holding the message, only its holder may renew or settle it
+	LeaseId   uuid.NullUUID `json:"-" gorm:"type:uuid;null;"`
+	LastError string        `json:"lastError,omitempty" gorm:"type:text;"`
+	SentAt    *time.Time    `json:"sentAt,omitempty" gorm:"null;"`
+}
+
+func (OutboxMessage) TableName() string {
+	return "mail_outbox"
+}
+
+var ErrLeaseLost = errors.New("outbox lease was taken over by another claim")
+
+// OutboxStore persists the outbox, it joins the transaction of ctx so a mail is only queued when its change commits
+type OutboxStore interface {
+	// Enqueue stores message unless one with the same idempotency key exists, it reports whether it was stored
+	Enqueue(ctx context.Context, message *OutboxMessage) (bool, error)
+	// Claim leases up to limit due messages until leaseUntil, messages of a lease that ran out are due again
+	Claim(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]OutboxMessage, error)
+	// Renew extends the lease of a claimed message, it reports false once another claim took the message over
+	Renew(ctx context.Context, message *OutboxMessage, leaseUntil time.Time) (bool, error)
+	// Save stores the outcome, a leased message is only saved while its lease is still held, or ErrLeaseLost
+	Save(ctx context.Context, message *OutboxMessage) error
+	Find(ctx context.Context, id uuid.UUID) (*OutboxMessage, error)
+	// PurgeSent deletes up to limit messages sent before the given time and returns how many it deleted
+	PurgeSent(ctx context.Context, before time.Time, limit int) (int64, error)
+}
+
+// OutboxMailer queues mails in the outbox, the outbox worker delivers them. Suppressed recipients are left out.
+type OutboxMailer struct {
+	store        OutboxStore
+	suppressions SuppressionStore
+	worker       *OutboxWorker
+}
+
+func NewOutboxMailer(store OutboxStore, suppressions SuppressionStore, worker *OutboxWorker) Mailer {
+	return &OutboxMailer{store: store, suppressions: suppressions, worker: worker}
+}
+
+func (m *OutboxMailer) SendText(ctx context.Context, to, subject, body string) error {
+	return m.enqueue(ctx, &Message{To: to, Subject: subject, Text: body})
+}
+
+func (m *OutboxMailer) SendHTML(ctx context.Context, to, subject, htmlBody string) error {
+	return m.enqueue(ctx, &Message{To: to, Subject: subject, HTML: htmlBody})
+}
+
+func (m *OutboxMailer) Send(ctx context.Context, message *Message) error {
+	return m.enqueue(ctx, message)
+}
+
+func (m *OutboxMailer) enqueue(ctx context.Context, message *Message) error {
+	message, err := withoutSuppressed(ctx, m.suppressions, message)
+	if err != nil {
+		return err
+	}
+	key, ok := IdempotencyKeyFromContext(ctx)
+	if !ok {
+		key = uuid.NewString()
+	}
+	queued, err := m.store.Enqueue(ctx, NewOutboxMessage(key, message))
+	if err != nil {
+		return err
+	}
+	if queued {
+		m.worker.Wake()
+	}
+	return nil
+}
+
+func NewOutboxMessage(idempotencyKey string, message *Message) *OutboxMessage {
+	return &OutboxMessage{
+		BaseAuditTrackingEntity: entity.NewSQLModel(),
+		IdempotencyKey:          idempotencyKey,
+		Recipient:               message.To,
+		Subject:                 message.Subject,
+		Payload:                 *message,
+		Status:                  OutboxPending,
+		NextAttemptAt:           time.Now().UTC(),
+	}
+}
+
+// Resend queues a sent or dead message again with a fresh attempt budget
+func (m *OutboxMessage) Resend(now time.Time) {
+	m.Status = OutboxPending
+	m.Attempts = 0
+	m.NextAttemptAt = now
+	m.LockedUntil = nil
+	m.LeaseId = uuid.NullUUID{}
+	m.LastError = ""
+}
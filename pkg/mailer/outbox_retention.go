@@ -0,0 +1,57 @@
+package mailer
+
+import (
+	"context"
+	"time"
+
+	"backend/pkg/cache"
+	configs "backend/pkg/config"
+	"backend/pkg/logger"
+)
+
+const (
+	defaultOutboxRetention = 30 * 24 * time.Hour
+	outboxRetentionEvery   = time.Hour
+	outboxRetentionBatch   = 1000
+)
+
+// RegisterOutboxRetention makes the elected leader delete the sent messages older than the retention once an hour,
+// dead messages are kept for the admin to look into. Each batch is fenced, a leader whose term ended stops deleting.
+func RegisterOutboxRetention(election *cache.LeaderElection, store OutboxStore, appConfig *configs.AppConfig, logger logger.Logger) {
+	retention := defaultOutboxRetention
+	if days := appConfig.Mail.Outbox.RetentionDays; days > 0 {
+		retention = time.Duration(days) * 24 * time.Hour
+	}
+	election.Go(func(ctx context.Context, token int64) {
+		ticker := time.NewTicker(outboxRetentionEvery)
+		defer ticker.Stop()
+		for {
+			deleted, err := purgeSent(ctx, election, token, store, time.Now().UTC().Add(-retention))
+			if err != nil && ctx.Err() == nil {
+				logger.Errorf("mail outbox retention: %v", err)
+			}
+			if deleted > 0 {
+				logger.Infof("mail outbox retention deleted %d sent messages", deleted)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+func purgeSent(ctx context.Context, election *cache.LeaderElection, token int64, store OutboxStore, before time.Time) (int64, error) {
+	var total int64
+	for {
+		if err := election.Fenced(ctx, token); err != nil {
+			return total, err
+		}
+		deleted, err := store.PurgeSent(ctx, before, outboxRetentionBatch)
+		total += deleted
+		if err != nil || deleted < outboxRetentionBatch {
+			return total, err
+		}
+	}
+}
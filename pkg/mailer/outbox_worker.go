@@ -0,0 +1,207 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	configs "backend/pkg/config"
+	"backend/pkg/logger"
+
+	"go.uber.org/fx"
+)
+
+const (
+	defaultOutboxPollInterval = 5 * time.Second
+	defaultOutboxBatchSize    = 20
+	defaultOutboxMaxAttempts  = 8
+	defaultOutboxBaseBackoff  = 30 * time.Second
+	defaultOutboxMaxBackoff   = 6 * time.Hour
+	defaultOutboxLease        = 2 * time.Minute
+)
+
+// OutboxWorker delivers due outbox messages. Failed deliveries are retried with exponential backoff
+// until the attempts run out and the message is dead-lettered. Replicas may run workers side by side, claims are exclusive.
+type OutboxWorker struct {
+	store       OutboxStore
+	transport   Transport
+	logger      logger.Logger
+	interval    time.Duration
+	batchSize   int
+	maxAttempts int
+	baseBackoff time.Duration
+	maxBackoff  time.Duration
+	lease       time.Duration
+	wake        chan struct{}
+	cancel      context.CancelFunc
+	done        sync.WaitGroup
+}
+
+func NewOutboxWorker(store OutboxStore, transport Transport, appConfig *configs.AppConfig, logger logger.Logger) *OutboxWorker {
+	config := appConfig.Mail.Outbox
+	return &OutboxWorker{
+		store:       store,
+		transport:   transport,
+		logger:      logger,
+		interval:    seconds(config.PollInterval, defaultOutboxPollInterval),
+		batchSize:   positive(config.BatchSize, defaultOutboxBatchSize),
+		maxAttempts: positive(config.MaxAttempts, defaultOutboxMaxAttempts),
+		baseBackoff: seconds(config.BaseBackoff, defaultOutboxBaseBackoff),
+		maxBackoff:  seconds(config.MaxBackoff, defaultOutboxMaxBackoff),
+		lease:       seconds(config.Lease, defaultOutboxLease),
+		wake:        make(chan struct{}, 1),
+	}
+}
+
+// RegisterOutboxWorker runs the worker from app start to app stop
+func RegisterOutboxWorker(lc fx.Lifecycle, worker *OutboxWorker) {
+	lc.Append(fx.Hook{
+		OnStart: func(_ context.Context) error {
+			worker.Start()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			return worker.Stop(ctx)
+		},
+	})
+}
+
+func (w *OutboxWorker) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	w.done.Add(1)
+	go w.run(ctx)
+}
+
+// Stop waits for the deliveries in flight, unfinished claims are picked up again once their lease runs out
+func (w *OutboxWorker) Stop(ctx context.Context) error {
+	if w.cancel == nil {
+		return nil
+	}
+	w.cancel()
+	finished := make(chan struct{})
+	go func() {
+		w.done.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Wake makes the worker poll right away instead of waiting for the next interval
+func (w *OutboxWorker) Wake() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (w *OutboxWorker) run(ctx context.Context) {
+	defer w.done.Done()
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		// a full batch hints at a backlog, keep going until it drains
+		for w.poll(ctx) == w.batchSize {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-w.wake:
+		}
+	}
+}
+
+// poll delivers one batch and returns its size
+func (w *OutboxWorker) poll(ctx context.Context) int {
+	if ctx.Err() != nil {
+		return 0
+	}
+	now := time.Now().UTC()
+	messages, err := w.store.Claim(ctx, now, now.Add(w.lease), w.batchSize)
+	if err != nil {
+		w.logger.Errorf("mail outbox claim: %v", err)
+		return 0
+	}
+	for i := range messages {
+		w.deliver(ctx, &messages[i])
+	}
+	return len(messages)
+}
+
+// deliver renews the lease right before sending, the batch may have waited behind slow sends and a lease
+// that ran out meanwhile could have been claimed by another worker. The send is capped at half the lease.
+func (w *OutboxWorker) deliver(ctx context.Context, message *OutboxMessage) {
+	if ctx.Err() != nil {
+		return
+	}
+	held, err := w.store.Renew(ctx, message, time.Now().UTC().Add(w.lease))
+	if err != nil {
+		w.logger.Errorf("mail outbox %s renew: %v", message.Id, err)
+		return
+	}
+	if !held {
+		return
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, w.lease/2)
+	err = w.transport.Send(sendCtx, &message.Payload)
+	cancel()
+
+	now := time.Now().UTC()
+	message.Attempts++
+	message.LockedUntil = nil
+	if err == nil {
+		message.Status = OutboxSent
+		message.SentAt = &now
+		message.LastError = ""
+	} else if message.Attempts >= w.maxAttempts {
+		message.Status = OutboxDead
+		message.LastError = err.Error()
+		w.logger.Errorf("mail outbox %s dead after %d attempts: %v", message.Id, message.Attempts, err)
+	} else {
+		message.Status = OutboxPending
+		message.NextAttemptAt = now.Add(w.backoff(message.Attempts))
+		message.LastError = err.Error()
+		w.logger.Warnf("mail outbox %s attempt %d failed: %v", message.Id, message.Attempts, err)
+	}
+
+	// saved even when stopping, the attempt happened
+	if err := w.store.Save(context.WithoutCancel(ctx), message); errors.Is(err, ErrLeaseLost) {
+		w.logger.Warnf("mail outbox %s lease lost before its outcome was saved", message.Id)
+	} else if err != nil {
+		w.logger.Errorf("mail outbox %s save: %v", message.Id, err)
+	}
+}
+
+// backoff doubles the delay with every attempt up to the max, with jitter so failed batches spread out
+func (w *OutboxWorker) backoff(attempts int) time.Duration {
+	delay := w.baseBackoff
+	for i := 1; i < attempts && delay < w.maxBackoff; i++ {
+		delay *= 2
+	}
+	delay = min(delay, w.maxBackoff)
+	return delay - time.Duration(rand.Int63n(int64(delay)/5+1))
+}
+
+func seconds(value int, fallback time.Duration) time.Duration {
+	if value <= 0 {
+		return fallback
+	}
+	return time.Duration(value) * time.Second
+}
+
+func positive(value int, fallback int) int {
+	if value <= 0 {
+		return fallback
+	}
+	return value
+}
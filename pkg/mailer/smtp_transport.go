@@ -0,0 +1,334 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+
+	configs "backend/pkg/config"
+
+	"go.uber.org/fx"
+)
+
+const (
+	SMTP_MODE_TLS      = "tls"
+	SMTP_MODE_STARTTLS = "starttls"
+	SMTP_MODE_PLAIN    = "plain"
+
+	SMTP_AUTH_PLAIN    = "plain"
+	SMTP_AUTH_LOGIN    = "login"
+	SMTP_AUTH_CRAM_MD5 = "cram-md5"
+	SMTP_AUTH_NONE     = "none"
+
+	defaultSMTPTimeout     = 30 * time.Second
+	defaultSMTPIdleTimeout = 60 * time.Second
+)
+
+// SMTPTransport delivers over SMTP with implicit TLS (465), STARTTLS (587) or plain SMTP for local catchers.
+// Connections are reused while idle for less than IdleTimeout, up to PoolSize of them.
+type SMTPTransport struct {
+	Host        string
+	Port        string
+	Username    string
+	Password    string
+	Mode        string
+	Auth        string
+	LocalName   string
+	Timeout     time.Duration
+	IdleTimeout time.Duration
+	TLSConfig   *tls.Config
+	composer    *Composer
+	idle        chan *smtpConn
+}
+
+type smtpConn struct {
+	conn     net.Conn
+	client   *smtp.Client
+	lastUsed time.Time
+}
+
+func NewSMTPTransport(lc fx.Lifecycle, appConfig *configs.AppConfig, composer *Composer) (Transport, error) {
+	transport, err := newSMTPTransport(appConfig.Smtp, composer)
+	if err != nil {
+		return nil, err
+	}
+	lc.Append(fx.Hook{
+		OnStop: func(_ context.Context) error {
+			transport.Close()
+			return nil
+		},
+	})
+	return transport, nil
+}
+
+func newSMTPTransport(config configs.SMTPConfig, composer *Composer) (*SMTPTransport, error) {
+	mode := strings.ToLower(config.Mode)
+	switch mode {
+	case "":
+		mode = SMTP_MODE_TLS
+	case SMTP_MODE_TLS, SMTP_MODE_STARTTLS, SMTP_MODE_PLAIN:
+	default:
+		return nil, fmt.Errorf("unknown smtp mode %q", config.Mode)
+	}
+	auth := strings.ToLower(config.Auth)
+	switch auth {
+	case "":
+		auth = SMTP_AUTH_PLAIN
+	case SMTP_AUTH_PLAIN, SMTP_AUTH_LOGIN, SMTP_AUTH_CRAM_MD5, SMTP_AUTH_NONE:
+	default:
+		return nil, fmt.Errorf("unknown smtp auth %q", config.Auth)
+	}
+
+	timeout := time.Duration(config.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = defaultSMTPTimeout
+	}
+	idleTimeout := time.Duration(config.IdleTimeout) * time.Second
+	if idleTimeout <= 0 {
+		idleTimeout = defaultSMTPIdleTimeout
+	}
+
+	return &SMTPTransport{
+		Host:        config.Host,
+		Port:        config.Port,
+		Username:    config.UserName,
+		Password:    config.Password,
+		Mode:        mode,
+		Auth:        auth,
+		LocalName:   config.LocalName,
+		Timeout:     timeout,
+		IdleTimeout: idleTimeout,
+		TLSConfig: &tls.Config{
+			ServerName:         config.Host,
+			MinVersion:         tls.VersionTLS12,
+			InsecureSkipVerify: config.InsecureSkipVerify,
+		},
+		composer: composer,
+		idle:     make(chan *smtpConn, max(config.PoolSize, 0)),
+	}, nil
+}
+
+func (m *SMTPTransport) Send(ctx context.Context, message *Message) error {
+	msg, err := m.composer.Compose(message, time.Now())
+	if err != nil {
+		return err
+	}
+	from := m.composer.From()
+	if message.From != "" {
+		from = message.From
+	}
+	return m.sendEmail(ctx, from, message.Recipients(), msg)
+}
+
+// Close quits the idle connections
+func (m *SMTPTransport) Close() {
+	for {
+		select {
+		case conn := <-m.idle:
+			conn.quit()
+		default:
+			return
+		}
+	}
+}
+
+func (m *SMTPTransport) sendEmail(ctx context.Context, from string, recipients []string, msg []byte) error {
+	envelopeFrom, err := mail.ParseAddress(from)
+	if err != nil {
+		return fmt.Errorf("invalid from address %q: %w", from, err)
+	}
+
+	conn, err := m.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// a cancelled ctx closes the connection, unblocking the command in flight
+	stop := context.AfterFunc(ctx, func() { conn.conn.Close() })
+	err = m.deliver(conn.client, envelopeFrom.Address, recipients, msg)
+	if !stop() {
+		conn.conn.Close()
+		return errors.Join(ctx.Err(), err)
+	}
+	if err != nil {
+		conn.conn.Close()
+		return err
+	}
+	m.release(conn)
+	return nil
+}
+
+func (m *SMTPTransport) deliver(client *smtp.Client, from string, recipients []string, msg []byte) error {
+	if err := client.Mail(from); err != nil {
+		return fmt.Errorf("lỗi MAIL FROM: %v", err)
+	}
+	for _, recipient := range recipients {
+		address, err := mail.ParseAddress(recipient)
+		if err != nil {
+			return fmt.Errorf("invalid address %q: %w", recipient, err)
+		}
+		if err = client.Rcpt(address.Address); err != nil {
+			return fmt.Errorf("lỗi RCPT TO: %v", err)
+		}
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("lỗi mở writer: %v", err)
+	}
+	if _, err = writer.Write(msg); err != nil {
+		return fmt.Errorf("lỗi ghi nội dung email: %v", err)
+	}
+	return writer.Close()
+}
+
+// acquire reuses an idle connection that still answers RSET, or dials a new one
+func (m *SMTPTransport) acquire(ctx context.Context) (*smtpConn, error) {
+	for {
+		select {
+		case conn := <-m.idle:
+			if time.Since(conn.lastUsed) > m.IdleTimeout {
+				conn.quit()
+				continue
+			}
+			conn.conn.SetDeadline(m.deadline(ctx))
+			if err := conn.client.Reset(); err != nil {
+				conn.conn.Close()
+				continue
+			}
+			return conn, nil
+		default:
+			return m.dial(ctx)
+		}
+	}
+}
+
+func (m *SMTPTransport) release(conn *smtpConn) {
+	conn.lastUsed = time.Now()
+	conn.conn.SetDeadline(time.Time{})
+	select {
+	case m.idle <- conn:
+	default:
+		conn.quit()
+	}
+}
+
+func (m *SMTPTransport) dial(ctx context.Context) (*smtpConn, error) {
+	addr := net.JoinHostPort(m.Host, m.Port)
+	dialer := &net.Dialer{Timeout: m.Timeout}
+
+	var conn net.Conn
+	var err error
+	if m.Mode == SMTP_MODE_TLS {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: m.TLSConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to SMTP server %s: %w", addr, err)
+	}
+	conn.SetDeadline(m.deadline(ctx))
+
+	client, err := m.handshake(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &smtpConn{conn: conn, client: client}, nil
+}
+
+func (m *SMTPTransport) handshake(conn net.Conn) (*smtp.Client, error) {
+	client, err := smtp.NewClient(conn, m.Host)
+	if err != nil {
+		return nil, fmt.Errorf("lỗi tạo SMTP client: %v", err)
+	}
+	if m.LocalName != "" {
+		if err := client.Hello(m.LocalName); err != nil {
+			return nil, fmt.Errorf("smtp HELO: %w", err)
+		}
+	}
+	if m.Mode == SMTP_MODE_STARTTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return nil, fmt.Errorf("smtp server %s does not support STARTTLS", m.Host)
+		}
+		if err := client.StartTLS(m.TLSConfig); err != nil {
+			return nil, fmt.Errorf("smtp STARTTLS: %w", err)
+		}
+	}
+
+	auth := m.auth()
+	if auth == nil {
+		return client, nil
+	}
+	if err := client.Auth(auth); err != nil {
+		return nil, fmt.Errorf("lỗi xác thực SMTP: %v", err)
+	}
+	return client, nil
+}
+
+func (m *SMTPTransport) auth() smtp.Auth {
+	switch m.Auth {
+	case SMTP_AUTH_NONE:
+		return nil
+	case SMTP_AUTH_LOGIN:
+		return &loginAuth{username: m.Username, password: m.Password, host: m.Host}
+	case SMTP_AUTH_CRAM_MD5:
+		return smtp.CRAMMD5Auth(m.Username, m.Password)
+	default:
+		return smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+}
+
+// deadline bounds every command by ctx and the configured timeout
+func (m *SMTPTransport) deadline(ctx context.Context) time.Time {
+	deadline := time.Now().Add(m.Timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		return ctxDeadline
+	}
+	return deadline
+}
+
+func (c *smtpConn) quit() {
+	c.conn.SetDeadline(time.Now().Add(time.Second))
+	if err := c.client.Quit(); err != nil {
+		c.conn.Close()
+	}
+}
+
+// loginAuth implements the LOGIN mechanism, which net/smtp lacks. Like PLAIN it needs TLS unless the server is local.
+type loginAuth struct {
+	username string
+	password string
+	host     string
+}
+
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS && !isLocalhost(server.Name) {
+		return "", nil, errors.New("unencrypted connection")
+	}
+	if server.Name != a.host {
+		return "", nil, errors.New("wrong host name")
+	}
+	return "LOGIN", nil, nil
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+	switch strings.ToLower(strings.TrimSpace(string(fromServer))) {
+	case "username:":
+		return []byte(a.username), nil
+	case "password:":
+		return []byte(a.password), nil
+	}
+	return nil, fmt.Errorf("unexpected LOGIN challenge %q", fromServer)
+}
+
+func isLocalhost(name string) bool {
+	return name == "localhost" || name == "127.0.0.1" || name == "::1"
+}
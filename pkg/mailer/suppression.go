@@ -0,0 +1,95 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+
+	"backend/pkg/encryption"
+	"backend/pkg/entity"
+
+	"gorm.io/gorm"
+)
+
+const (
+	SuppressionBounce    = "BOUNCE"
+	SuppressionComplaint = "COMPLAINT"
+)
+
+var ErrRecipientSuppressed = errors.New("recipient address is suppressed")
+
+// Suppression is an address no mail goes to anymore, after it hard bounced or complained about spam.
+// The address is encrypted and looked up by its blind index.
+type Suppression struct {
+	entity.BaseAuditTrackingEntity
+	AddressIndex string `json:"-" gorm:"type:varchar(64);not null;uniqueIndex;"`
+	Address      string `json:"address" gorm:"type:text;not null;serializer:encrypted;"`
+	Reason       string `json:"reason" gorm:"type:varchar(20);not null;"`
+	Provider     string `json:"provider" gorm:"type:varchar(20);not null;"`
+	Detail       string `json:"detail,omitempty" gorm:"type:text;"`
+}
+
+func (Suppression) TableName() string {
+	return "mail_suppressions"
+}
+
+// BeforeSave keeps the blind index of the address in step with it
+func (s *Suppression) BeforeSave(tx *gorm.DB) error {
+	index, err := encryption.BlindIndex(s.Address)
+	if err != nil {
+		return err
+	}
+	s.AddressIndex = index
+	return nil
+}
+
+// SuppressionStore persists the suppressed addresses
+type SuppressionStore interface {
+	// Suppress records the address, an address already suppressed keeps its first record
+	Suppress(ctx context.Context, suppression *Suppression) error
+	// Suppressed returns which of addresses are suppressed
+	Suppressed(ctx context.Context, addresses []string) (map[string]bool, error)
+}
+
+// withoutSuppressed drops the suppressed Cc and Bcc recipients, a suppressed To fails with ErrRecipientSuppressed
+func withoutSuppressed(ctx context.Context, store SuppressionStore, message *Message) (*Message, error) {
+	recipients := message.Recipients()
+	addresses := make([]string, 0, len(recipients))
+	for _, recipient := range recipients {
+		addresses = append(addresses, bareAddress(recipient))
+	}
+	suppressed, err := store.Suppressed(ctx, addresses)
+	if err != nil {
+		return nil, err
+	}
+	if len(suppressed) == 0 {
+		return message, nil
+	}
+	if suppressed[bareAddress(message.To)] {
+		return nil, ErrRecipientSuppressed
+	}
+
+	filtered := *message
+	filtered.Cc = keepUnsuppressed(message.Cc, suppressed)
+	filtered.Bcc = keepUnsuppressed(message.Bcc, suppressed)
+	return &filtered, nil
+}
+
+func keepUnsuppressed(recipients []string, suppressed map[string]bool) []string {
+	var kept []string
+	for _, recipient := range recipients {
+		if !suppressed[bareAddress(recipient)] {
+			kept = append(kept, recipient)
+		}
+	}
+	return kept
+}
+
+// bareAddress reduces "Name <user@host>" to user@host, an unparsable value is returned as is
+func bareAddress(address string) string {
+	parsed, err := mail.ParseAddress(address)
+	if err != nil {
+		return encryption.Normalize(address)
+	}
+	return encryption.Normalize(parsed.Address)
+}
@@ -0,0 +1,40 @@
+package mailer
+
+import (
+	"fmt"
+
+	configs "backend/pkg/config"
+
+	"go.uber.org/fx"
+)
+
+const (
+	TRANSPORT_SMTP   = "smtp"
+	TRANSPORT_HTTP   = "http"
+	TRANSPORT_FILE   = "file"
+	TRANSPORT_MEMORY = "memory"
+)
+
+// NewTransport builds the transport selected by the mail transport setting.
+// The memory transport is shared so the development mailbox shows what was sent.
+func NewTransport(lc fx.Lifecycle, appConfig *configs.AppConfig, composer *Composer, memory *MemoryTransport) (Transport, error) {
+	switch appConfig.Mail.Transport {
+	case TRANSPORT_SMTP, "":
+		return NewSMTPTransport(lc, appConfig, composer)
+	case TRANSPORT_HTTP:
+		return NewHTTPTransport(appConfig.Mail.HTTP, composer)
+	case TRANSPORT_FILE:
+		return NewFileTransport(appConfig.Mail.File.Dir, composer)
+	case TRANSPORT_MEMORY:
+		return memory, nil
+	}
+	return nil, fmt.Errorf("unknown mail transport %q", appConfig.Mail.Transport)
+}
+
+// sender is the default From of the mails
+func sender(appConfig *configs.AppConfig) string {
+	if appConfig.Mail.From != "" {
+		return appConfig.Mail.From
+	}
+	return appConfig.Smtp.From
+}
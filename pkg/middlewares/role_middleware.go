@@ -0,0 +1,36 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type RoleChecker interface {
+	// HasRole reports whether the user holds the global role
+	HasRole(ctx context.Context, userId uuid.UUID, role string) (bool, error)
+}
+
+// RequireRoleMiddleware only lets users holding one of roles through, it runs after ValidateTokenMiddleware
+func RequireRoleMiddleware(checker RoleChecker, roles ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			currentUser, ok := c.Get("currentUser").(CurrentUser)
+			if !ok {
+				return echo.NewHTTPError(http.StatusUnauthorized, "missing current user")
+			}
+			for _, role := range roles {
+				has, err := checker.HasRole(c.Request().Context(), currentUser.UserId, role)
+				if err != nil {
+					return echo.NewHTTPError(http.StatusInternalServerError, "can not check the roles of the user")
+				}
+				if has {
+					return next(c)
+				}
+			}
+			return echo.NewHTTPError(http.StatusForbidden, "user is not allowed to access this resource")
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package middlewares
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+
+	configs "backend/pkg/config"
+	"backend/pkg/database"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+const HeaderTenantID = "X-Tenant-ID"
+
+type TenantResolver interface {
+	// ResolveTenant maps a tenant id or slug to the id of an active tenant
+	ResolveTenant(ctx context.Context, key string) (uuid.UUID, error)
+}
+
+// TenantMiddleware resolves the tenant of the request from the tenant header or the subdomain and carries it in the request context.
+// Authenticated requests without either fall back to the tenant claim of their token in ValidateTokenMiddleware.
+func TenantMiddleware(appConfig *configs.AppConfig, resolver TenantResolver) echo.MiddlewareFunc {
+	config := appConfig.Tenant
+	header := config.Header
+	if header == "" {
+		header = HeaderTenantID
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := strings.TrimSpace(c.Request().Header.Get(header))
+			if key == "" {
+				key = subdomain(c.Request().Host, config.BaseDomain)
+			}
+			if key == "" {
+				return next(c)
+			}
+
+			ctx := c.Request().Context()
+			tenantId, err := resolver.ResolveTenant(ctx, key)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusNotFound, "tenant not found")
+			}
+			c.SetRequest(c.Request().WithContext(database.WithTenant(ctx, tenantId)))
+			return next(c)
+		}
+	}
+}
+
+func subdomain(host string, baseDomain string) string {
+	if baseDomain == "" {
+		return ""
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(host)
+	suffix := "." + strings.ToLower(strings.TrimPrefix(baseDomain, "."))
+	if !strings.HasSuffix(host, suffix) {
+		return ""
+	}
+	label := strings.TrimSuffix(host, suffix)
+	if label == "" || label == "www" || strings.Contains(label, ".") {
+		return ""
+	}
+	return label
+}
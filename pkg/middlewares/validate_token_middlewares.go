@@ -6,6 +6,7 @@ import (
 
 	"backend/pkg/cache"
 	configs "backend/pkg/config"
+	"backend/pkg/database"
 	"backend/pkg/jwt_generate"
 
 	"github.com/google/uuid"
@@ -13,8 +14,9 @@ import (
 )
 
 type CurrentUser struct {
-	UserId uuid.UUID
-	Email  string
+	UserId   uuid.UUID
+	Email    string
+	TenantId uuid.UUID
 }
 
 func ValidateTokenMiddleware(appConfig *configs.AppConfig, redisCache cache.Cache) echo.MiddlewareFunc {
@@ -30,10 +32,22 @@ func ValidateTokenMiddleware(appConfig *configs.AppConfig, redisCache cache.Cach
 				return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
 			}
 			currentUser := CurrentUser{
-				UserId: token.UserId,
-				Email:  token.Email,
+				UserId:   token.UserId,
+				Email:    token.Email,
+				TenantId: token.TenantId,
 			}
+
+			ctx := database.WithActor(c.Request().Context(), currentUser.UserId)
+			if tenantId, ok := database.TenantFromContext(ctx); ok {
+				if tenantId != token.TenantId {
+					return echo.NewHTTPError(http.StatusForbidden, "token is not issued for this tenant")
+				}
+			} else if token.TenantId != uuid.Nil {
+				ctx = database.WithTenant(ctx, token.TenantId)
+			}
+
 			c.Set("currentUser", currentUser)
+			c.SetRequest(c.Request().WithContext(ctx))
 			return next(c)
 		}
 	}
@@ -0,0 +1,126 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+const memorySweepEvery = 1024
+
+// MemoryStore keeps counters in process, limits are per instance so it only suits a single node
+type MemoryStore struct {
+	mu      sync.Mutex
+	windows map[string]*memoryWindow
+	buckets map[string]*memoryBucket
+	calls   int
+}
+
+type memoryWindow struct {
+	hits      []time.Time
+	expiresAt time.Time
+}
+
+type memoryBucket struct {
+	tokens    float64
+	updatedAt time.Time
+	expiresAt time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		windows: make(map[string]*memoryWindow),
+		buckets: make(map[string]*memoryBucket),
+	}
+}
+
+func (s *MemoryStore) Allow(ctx context.Context, key string, rule Rule, now time.Time) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls++
+	if s.calls%memorySweepEvery == 0 {
+		s.sweep(now)
+	}
+
+	switch rule.Algorithm {
+	case ALGORITHM_SLIDING_WINDOW:
+		return s.slidingWindow(key, rule, now), nil
+	case ALGORITHM_TOKEN_BUCKET:
+		return s.tokenBucket(key, rule, now), nil
+	default:
+		return Result{}, fmt.Errorf("unknown algorithm %q", rule.Algorithm)
+	}
+}
+
+func (s *MemoryStore) slidingWindow(key string, rule Rule, now time.Time) Result {
+	window, ok := s.windows[key]
+	if !ok {
+		window = &memoryWindow{}
+		s.windows[key] = window
+	}
+
+	start := now.Add(-rule.Window)
+	kept := window.hits[:0]
+	for _, hit := range window.hits {
+		if hit.After(start) {
+			kept = append(kept, hit)
+		}
+	}
+	window.hits = kept
+
+	allowed := len(window.hits) < rule.Limit
+	if allowed {
+		window.hits = append(window.hits, now)
+		window.expiresAt = now.Add(rule.Window)
+	}
+
+	result := Result{Allowed: allowed, Limit: rule.Limit, Remaining: rule.Limit - len(window.hits)}
+	if len(window.hits) > 0 {
+		result.Reset = window.hits[len(window.hits)-1].Add(rule.Window).Sub(now)
+		if !allowed {
+			result.RetryAfter = window.hits[0].Add(rule.Window).Sub(now)
+		}
+	}
+	return result
+}
+
+func (s *MemoryStore) tokenBucket(key string, rule Rule, now time.Time) Result {
+	capacity := float64(rule.Limit)
+	rate := capacity / float64(rule.Window)
+
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = &memoryBucket{tokens: capacity, updatedAt: now}
+		s.buckets[key] = bucket
+	}
+	bucket.tokens = math.Min(capacity, bucket.tokens+float64(now.Sub(bucket.updatedAt))*rate)
+	bucket.updatedAt = now
+	bucket.expiresAt = now.Add(rule.Window)
+
+	result := Result{Limit: rule.Limit}
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration(math.Ceil((1 - bucket.tokens) / rate))
+	}
+	result.Remaining = int(bucket.tokens)
+	result.Reset = time.Duration(math.Ceil((capacity - bucket.tokens) / rate))
+	return result
+}
+
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, window := range s.windows {
+		if now.After(window.expiresAt) {
+			delete(s.windows, key)
+		}
+	}
+	for key, bucket := range s.buckets {
+		if now.After(bucket.expiresAt) {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package ratelimit
+
+import (
+	"backend/pkg/middlewares"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	app_errors "backend/pkg/errors"
+	"backend/pkg/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
+)
+
+// Middleware enforces the rules of group, requests over the limit get 429 with Retry-After.
+// A rule is skipped when its key is absent from the request, store failures let the request through.
+func (l *Limiter) Middleware(group string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			rules := l.rules[group]
+			if len(rules) == 0 {
+				return next(c)
+			}
+
+			ctx := c.Request().Context()
+			now := time.Now()
+			var tightest *Result
+			for index, rule := range rules {
+				value, ok := l.keyValue(c, rule.Key)
+				if !ok {
+					continue
+				}
+				result, err := l.store.Allow(ctx, storeKey(group, index, rule, value), rule, now)
+				if err != nil {
+					l.logger.Warnf("rate limit group %s: %v", group, err)
+					continue
+				}
+				if tightest == nil || !result.Allowed || result.Remaining < tightest.Remaining {
+					tightest = &result
+				}
+				if !result.Allowed {
+					break
+				}
+			}
+			if tightest == nil {
+				return next(c)
+			}
+
+			header := c.Response().Header()
+			header.Set(HeaderRateLimitLimit, strconv.Itoa(tightest.Limit))
+			header.Set(HeaderRateLimitRemaining, strconv.Itoa(max(tightest.Remaining, 0)))
+			header.Set(HeaderRateLimitReset, seconds(tightest.Reset))
+			if !tightest.Allowed {
+				header.Set(echo.HeaderRetryAfter, seconds(tightest.RetryAfter))
+				return c.JSON(http.StatusTooManyRequests, response.Failure(app_errors.NewGeneralError(app_errors.RateLimited)))
+			}
+			return next(c)
+		}
+	}
+}
+
+func (l *Limiter) keyValue(c echo.Context, key string) (string, bool) {
+	switch key {
+	case KEY_IP:
+		// RealIP goes through the IP extractor of the server, which only trusts forwarded headers from known proxies
+		return c.RealIP(), true
+	case KEY_USER:
+		if currentUser, ok := c.Get("currentUser").(middlewares.CurrentUser); ok {
+			return currentUser.UserId.String(), true
+		}
+		return "", false
+	case KEY_API_KEY:
+		if apiKey := c.Request().Header.Get(l.apiKeyHeader); apiKey != "" {
+			return hash(apiKey), true
+		}
+		return "", false
+	case KEY_EMAIL:
+		if email := bodyEmail(c); email != "" {
+			return hash(strings.ToLower(strings.TrimSpace(email))), true
+		}
+		return "", false
+	}
+	return "", false
+}
+
+// bodyEmail peeks the email field of a JSON body and restores the body for the handler
+func bodyEmail(c echo.Context) string {
+	request := c.Request()
+	if request.Body == nil || !strings.HasPrefix(request.Header.Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+		return ""
+	}
+	body, err := io.ReadAll(request.Body)
+	request.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return ""
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return ""
+	}
+	for name, value := range fields {
+		if strings.EqualFold(name, "email") {
+			email, _ := value.(string)
+			return email
+		}
+	}
+	return ""
+}
+
+// hash keeps emails and api keys out of the store keys
+func hash(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:16])
+}
+
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,107 @@
+package ratelimit
+
+import (
+	"backend/pkg/cache"
+	configs "backend/pkg/config"
+	"backend/pkg/logger"
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	ALGORITHM_SLIDING_WINDOW = "sliding_window"
+	ALGORITHM_TOKEN_BUCKET   = "token_bucket"
+)
+
+const (
+	KEY_IP      = "ip"
+	KEY_USER    = "user"
+	KEY_EMAIL   = "email"
+	KEY_API_KEY = "apiKey"
+)
+
+type Rule struct {
+	Algorithm string
+	Key       string
+	Limit     int
+	Window    time.Duration
+}
+
+// Result is the outcome of one rule, Reset is the time until the key is fully replenished
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration
+	Reset      time.Duration
+}
+
+// Store counts hits atomically, key identifies the rule and the client
+type Store interface {
+	Allow(ctx context.Context, key string, rule Rule, now time.Time) (Result, error)
+}
+
+// Limiter applies the configured rules of a route group
+type Limiter struct {
+	store        Store
+	rules        map[string][]Rule
+	apiKeyHeader string
+	logger       logger.Logger
+}
+
+// NewLimiter keeps counters in Redis when the cache is Redis backed, otherwise in process memory
+func NewLimiter(appConfig *configs.AppConfig, appCache cache.Cache, logger logger.Logger) (*Limiter, error) {
+	config := appConfig.RateLimit
+	rules := make(map[string][]Rule)
+	if config.Enable {
+		for group, groupRules := range config.Rules {
+			for _, rule := range groupRules {
+				parsed, err := parseRule(rule)
+				if err != nil {
+					return nil, fmt.Errorf("rate limit group %s: %w", group, err)
+				}
+				rules[group] = append(rules[group], parsed)
+			}
+		}
+	}
+
+	var store Store
+	if client, ok := cache.RedisClientOf(appCache); ok {
+		store = NewRedisStore(client)
+	} else {
+		store = NewMemoryStore()
+	}
+
+	apiKeyHeader := config.ApiKeyHeader
+	if apiKeyHeader == "" {
+		apiKeyHeader = "X-API-Key"
+	}
+	return &Limiter{store: store, rules: rules, apiKeyHeader: apiKeyHeader, logger: logger}, nil
+}
+
+func parseRule(rule configs.RateLimitRule) (Rule, error) {
+	switch rule.Algorithm {
+	case ALGORITHM_SLIDING_WINDOW, ALGORITHM_TOKEN_BUCKET:
+	default:
+		return Rule{}, fmt.Errorf("unknown algorithm %q", rule.Algorithm)
+	}
+	switch rule.Key {
+	case KEY_IP, KEY_USER, KEY_EMAIL, KEY_API_KEY:
+	default:
+		return Rule{}, fmt.Errorf("unknown key %q", rule.Key)
+	}
+	if rule.Limit <= 0 || rule.Window <= 0 {
+		return Rule{}, fmt.Errorf("limit and window must be positive")
+	}
+	return Rule{
+		Algorithm: rule.Algorithm,
+		Key:       rule.Key,
+		Limit:     rule.Limit,
+		Window:    time.Duration(rule.Window) * time.Second,
+	}, nil
+}
+
+func storeKey(group string, index int, rule Rule, value string) string {
+	return cache.RateLimitKey.Build(fmt.Sprintf("%s:%d:%s:%s", group, index, rule.Key, value))
+}
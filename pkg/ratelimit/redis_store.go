@@ -0,0 +1,108 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+// slidingWindowScript keeps a sorted set of hit timestamps (ms) within the window.
+// KEYS[1] key, ARGV now, window, limit, member. Returns {allowed, remaining, retryAfter, reset}.
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+local count = redis.call('ZCARD', key)
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', key, now, ARGV[4])
+	redis.call('PEXPIRE', key, window)
+	count = count + 1
+	allowed = 1
+end
+
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+local reset = 0
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+local newest = redis.call('ZRANGE', key, -1, -1, 'WITHSCORES')
+local full = 0
+if newest[2] then
+	full = tonumber(newest[2]) + window - now
+end
+
+local retry = 0
+if allowed == 0 then
+	retry = reset
+end
+return {allowed, limit - count, retry, full}
+`)
+
+// tokenBucketScript refills limit tokens over window (ms) and takes one token per hit.
+// KEYS[1] key, ARGV now, window, limit. Returns {allowed, remaining, retryAfter, reset}.
+var tokenBucketScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local capacity = tonumber(ARGV[3])
+local rate = capacity / window
+
+local bucket = redis.call('HMGET', key, 'tokens', 'ts')
+local tokens = tonumber(bucket[1]) or capacity
+local ts = tonumber(bucket[2]) or now
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) / rate)
+end
+
+redis.call('HMSET', key, 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', key, window)
+return {allowed, math.floor(tokens), retry, math.ceil((capacity - tokens) / rate)}
+`)
+
+type RedisStore struct {
+	client redis.UniversalClient
+}
+
+func NewRedisStore(client redis.UniversalClient) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+func (s *RedisStore) Allow(ctx context.Context, key string, rule Rule, now time.Time) (Result, error) {
+	var script *redis.Script
+	args := []any{now.UnixMilli(), rule.Window.Milliseconds(), rule.Limit}
+	switch rule.Algorithm {
+	case ALGORITHM_SLIDING_WINDOW:
+		script = slidingWindowScript
+		args = append(args, fmt.Sprintf("%d-%s", now.UnixNano(), uuid.NewString()))
+	case ALGORITHM_TOKEN_BUCKET:
+		script = tokenBucketScript
+	default:
+		return Result{}, fmt.Errorf("unknown algorithm %q", rule.Algorithm)
+	}
+
+	values, err := script.Run(ctx, s.client, []string{key}, args...).Int64Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("error running rate limit script for key %s: %w", key, err)
+	}
+	return Result{
+		Allowed:    values[0] == 1,
+		Limit:      rule.Limit,
+		Remaining:  int(values[1]),
+		RetryAfter: time.Duration(values[2]) * time.Millisecond,
+		Reset:      time.Duration(values[3]) * time.Millisecond,
+	}, nil
+}
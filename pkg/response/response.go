@@ -16,6 +16,15 @@ type ResponseWithPaging[T any, P any] struct {
 	IsSuccess bool   `json:"isSuccess"`
 }
 
+type ResponseWithCursor[T any] struct {
+	Data       T      `json:"data"`
+	NextCursor string `json:"nextCursor,omitempty"`
+	PrevCursor string `json:"prevCursor,omitempty"`
+	Code       int    `json:"code"`
+	IsSuccess  bool   `json:"isSuccess"`
+	Message    string `json:"message"`
+}
+
 func generate[T any](data T, isSuccess bool, err errors.AppError) *Response[T] {
 	code := err.GetCode()
 	message := err.GetMessage(code)
@@ -33,3 +42,25 @@ func Failure(err errors.AppError) *Response[bool] {
 func FailureWithData[T any](data T, err errors.AppError) *Response[T] {
 	return generate(data, false, err)
 }
+
+func SuccessWithCursor[T any](data T, nextCursor string, prevCursor string) *ResponseWithCursor[T] {
+	result := generate(data, true, errors.NewGeneralError(errors.Success))
+	return &ResponseWithCursor[T]{
+		Data:       result.Data,
+		NextCursor: nextCursor,
+		PrevCursor: prevCursor,
+		Code:       result.Code,
+		IsSuccess:  result.IsSuccess,
+		Message:    result.Message,
+	}
+}
+
+func FailureWithCursor[T any](data T, err errors.AppError) *ResponseWithCursor[T] {
+	result := generate(data, false, err)
+	return &ResponseWithCursor[T]{
+		Data:      result.Data,
+		Code:      result.Code,
+		IsSuccess: result.IsSuccess,
+		Message:   result.Message,
+	}
+}
@@ -0,0 +1,193 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+var ErrInvalidCursor = errors.New("cursor is invalid")
+
+type CursorDirection string
+
+const (
+	CursorNext CursorDirection = "next"
+	CursorPrev CursorDirection = "prev"
+)
+
+// Cursor is the decoded form of an opaque page token: the sort key value and the id of the row at the page edge
+type Cursor struct {
+	SortKey   string          `json:"k"`
+	Desc      bool            `json:"o,omitempty"`
+	Value     json.RawMessage `json:"v"`
+	Id        json.RawMessage `json:"i"`
+	Direction CursorDirection `json:"d"`
+}
+
+// CursorPage holds the cursors pointing to the pages around the one just read
+type CursorPage struct {
+	Next *Cursor
+	Prev *Cursor
+}
+
+type CursorPagination struct {
+	Size    int    `query:"size" json:"size,omitempty"`
+	Cursor  string `query:"cursor" json:"cursor,omitempty"`
+	OrderBy string `query:"orderBy" json:"orderBy,omitempty"`
+
+	SortKey string  `json:"-"`
+	Desc    bool    `json:"-"`
+	After   *Cursor `json:"-"`
+}
+
+type CursorCodec struct {
+	secret []byte
+}
+
+// NewCursorCodec refuses an empty secret, anyone could forge cursors signed with it
+func NewCursorCodec(secret string) (*CursorCodec, error) {
+	if secret == "" {
+		return nil, errors.New("pagination.cursorSecretKey is not set")
+	}
+	return &CursorCodec{secret: []byte(secret)}, nil
+}
+
+// Encode signs the cursor and returns it as a base64 token
+func (c *CursorCodec) Encode(cursor *Cursor) (string, error) {
+	if cursor == nil {
+		return "", nil
+	}
+	payload, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(c.sign(encoded)), nil
+}
+
+// Decode verifies the token signature and returns the cursor it carries
+func (c *CursorCodec) Decode(token string) (*Cursor, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(sig, c.sign(encoded)) {
+		return nil, ErrInvalidCursor
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor Cursor
+	if err := json.Unmarshal(payload, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if cursor.Direction != CursorNext && cursor.Direction != CursorPrev {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// EncodePage returns the next and previous tokens of a page
+func (c *CursorCodec) EncodePage(page *CursorPage) (string, string, error) {
+	if page == nil {
+		return "", "", nil
+	}
+	next, err := c.Encode(page.Next)
+	if err != nil {
+		return "", "", err
+	}
+	prev, err := c.Encode(page.Prev)
+	if err != nil {
+		return "", "", err
+	}
+	return next, prev, nil
+}
+
+func (c *CursorCodec) sign(payload string) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+func ToCursorPagination(c echo.Context, codec *CursorCodec) (*CursorPagination, error) {
+	q := &CursorPagination{}
+	var size, cursor, orderBy string
+
+	err := echo.QueryParamsBinder(c).
+		String("size", &size).
+		String("cursor", &cursor).
+		String("orderBy", &orderBy).
+		BindError()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = q.SetSize(size); err != nil {
+		return nil, err
+	}
+	q.SetOrderBy(orderBy)
+
+	if cursor != "" {
+		after, err := codec.Decode(cursor)
+		if err != nil {
+			return nil, err
+		}
+		q.Cursor = cursor
+		q.After = after
+		q.SortKey = after.SortKey
+		q.Desc = after.Desc
+	}
+
+	return q, nil
+}
+
+// SetSize Set page size
+func (q *CursorPagination) SetSize(sizeQuery string) error {
+	if sizeQuery == "" {
+		q.Size = defaultSize
+		return nil
+	}
+	n, err := strconv.Atoi(sizeQuery)
+	if err != nil {
+		return err
+	}
+	if n <= 0 {
+		n = defaultSize
+	}
+	if n > maxSize {
+		n = maxSize
+	}
+	q.Size = n
+
+	return nil
+}
+
+// SetOrderBy Set sort key, accepts "field", "field asc" or "field desc"
+func (q *CursorPagination) SetOrderBy(orderByQuery string) {
+	q.OrderBy = orderByQuery
+	parts := strings.Fields(orderByQuery)
+	if len(parts) == 0 {
+		q.SortKey = ""
+		q.Desc = false
+		return
+	}
+	q.SortKey = parts[0]
+	q.Desc = len(parts) > 1 && strings.EqualFold(parts[1], "desc")
+}
+
+// GetLimit Get limit
+func (q *CursorPagination) GetLimit() int {
+	if q.Size <= 0 {
+		return defaultSize
+	}
+	return q.Size
+}